@@ -0,0 +1,324 @@
+package config
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+// Environment aplikasi (APP_ENV)
+const (
+	EnvDevelopment = "development"
+	EnvTest        = "test"
+	EnvStaging     = "staging"
+	EnvProduction  = "production"
+)
+
+// Config - seluruh konfigurasi aplikasi, di-load sekali saat start (lihat Load)
+//
+// Setiap field dibaca dari env dengan nama di tag `env`, default di tag `default`.
+// Field dengan tag `secret:"true"` tidak pernah ditampilkan di Redacted.
+type Config struct {
+	Env      string `env:"APP_ENV" default:"development"`
+	Server   ServerConfig
+	Database DatabaseConfig
+	JWT      JWTConfig
+	Mail     MailConfig
+	Public   PublicConfig
+	Password PasswordConfig
+	Security SecurityConfig
+	TOTP     TOTPConfig
+	CORS     CORSConfig
+
+	// RateLimits - override rate limit per policy dari env RATE_LIMIT_<NAME> ("N/durasi" atau "off"),
+	// key = nama env (lihat middlewares.RateLimit)
+	RateLimits map[string]string
+
+	sources map[string]string // nama env → asal nilai (default / file / env)
+}
+
+type ServerConfig struct {
+	Port int `env:"PORT" default:"8080"`
+
+	// Di belakang reverse proxy / load balancer keduanya WAJIB diisi: tanpa ini IP client = IP proxy,
+	// jadi semua user berbagi satu bucket rate limit dan counter gagal login per IP.
+	// Pakai header yang selalu ditimpa proxy (mis. X-Real-IP), X-Forwarded-For hanya jika proxy tidak meneruskan nilai dari client
+	ProxyHeader    string   `env:"PROXY_HEADER"`    // header berisi IP client asli, kosong = IP koneksi langsung
+	TrustedProxies []string `env:"TRUSTED_PROXIES"` // IP / CIDR proxy dipisah koma, ProxyHeader hanya dibaca dari request lewat proxy ini
+}
+
+type DatabaseConfig struct {
+	Host        string `env:"DB_HOST"`
+	Port        int    `env:"DB_PORT" default:"5432"`
+	User        string `env:"DB_USER"`
+	Password    string `env:"DB_PASSWORD" secret:"true"`
+	Name        string `env:"DB_NAME"`
+	SSLMode     string `env:"DB_SSLMODE" default:"disable"`
+	AutoMigrate bool   `env:"DB_AUTO_MIGRATE" default:"false"` // jalankan migration otomatis saat start
+}
+
+type JWTConfig struct {
+	// Secret - key HS256 (JWT_ALGORITHM=HS256 / token lama tanpa kid) dan kunci enkripsi private key di database
+	Secret           string        `env:"SECRET_JWT_AUTOVERS" secret:"true"`
+	Algorithm        string        `env:"JWT_ALGORITHM" default:"EdDSA"`            // EdDSA / RS256 (key pair dengan kid, dipublikasikan di JWKS) atau HS256
+	RotationInterval time.Duration `env:"JWT_KEY_ROTATION_INTERVAL" default:"720h"` // umur key pair sebelum diganti key baru
+	GracePeriod      time.Duration `env:"JWT_KEY_GRACE_PERIOD" default:"24h"`       // key lama masih bisa verify selama ini setelah diganti
+	LegacyHS256      bool          `env:"JWT_ACCEPT_LEGACY_HS256" default:"true"`   // terima token HS256 tanpa kid (sebelum key rotation), matikan setelah 1x AccessTokenTTL
+}
+
+type MailConfig struct {
+	Transport string `env:"MAIL_TRANSPORT" default:"smtp"` // smtp / file / memory
+	FileDir   string `env:"MAIL_FILE_DIR"`                 // transport file: folder .eml, kosong = hanya log
+	SMTPHost  string `env:"SMTP_HOST" default:"smtp.hostinger.com"`
+	SMTPPort  int    `env:"SMTP_PORT" default:"587"`
+	SMTPUser  string `env:"SMTP_USER"`
+	SMTPPass  string `env:"SMTP_PASS" secret:"true"`
+	From      string `env:"SMTP_FROM"`
+}
+
+// PublicConfig - URL frontend dan kontak support yang muncul di email
+type PublicConfig struct {
+	BaseURL            string `env:"PUBLIC_BASE_URL" default:"https://autovers.site"`
+	VerifyPath         string `env:"EMAIL_VERIFY_PATH" default:"/auth/verify"`
+	ResetPasswordPath  string `env:"EMAIL_RESET_PASSWORD_PATH" default:"/auth/reset-password"`
+	ForgotPasswordPath string `env:"EMAIL_FORGOT_PASSWORD_PATH" default:"/auth/forgot-password"`
+	UnlockPath         string `env:"EMAIL_UNLOCK_PATH" default:"/auth/unlock"`
+	SupportEmail       string `env:"SUPPORT_EMAIL"`
+	SupportURL         string `env:"SUPPORT_URL"`
+}
+
+type PasswordConfig struct {
+	MinLength     int    `env:"PASSWORD_MIN_LENGTH" default:"8"`
+	MaxBytes      int    `env:"PASSWORD_MAX_BYTES" default:"72"`
+	RequireUpper  bool   `env:"PASSWORD_REQUIRE_UPPER" default:"true"`
+	RequireLower  bool   `env:"PASSWORD_REQUIRE_LOWER" default:"true"`
+	RequireDigit  bool   `env:"PASSWORD_REQUIRE_DIGIT" default:"true"`
+	RequireSymbol bool   `env:"PASSWORD_REQUIRE_SYMBOL" default:"false"`
+	BreachedFile  string `env:"BREACHED_PASSWORDS_FILE"` // satu hash SHA-1 per baris ("HASH" atau "HASH:COUNT"), wajib terurut berdasarkan hash
+}
+
+type SecurityConfig struct {
+	LoginAttemptStore string `env:"LOGIN_ATTEMPT_STORE" default:"postgres"` // postgres / memory (single instance / testing)
+}
+
+// TOTPConfig - kunci enkripsi secret TOTP di database, sengaja terpisah dari SECRET_JWT_AUTOVERS
+// supaya rotasi secret JWT tidak membuat secret 2FA user tidak bisa dibaca
+type TOTPConfig struct {
+	// EncryptionKeys - daftar "id:key" dipisah koma, key pertama dipakai untuk enkripsi,
+	// key berikutnya hanya untuk membaca secret lama (dienkripsi ulang dengan key pertama saat user login dengan 2FA)
+	EncryptionKeys []string `env:"TOTP_ENCRYPTION_KEY" secret:"true"`
+}
+
+// EncryptionKey - satu key dari daftar "id:key"
+type EncryptionKey struct {
+	ID     string
+	Secret string
+}
+
+type CORSConfig struct {
+	AllowedOrigins []string `env:"ALLOWED_ORIGINS" default:"http://localhost:3000"` // dipisah koma
+}
+
+// IsProduction - APP_ENV=production
+func (c *Config) IsProduction() bool {
+	return c.Env == EnvProduction
+}
+
+// IsLocal - APP_ENV=development atau test, satu-satunya env yang boleh jalan dengan secret acak
+func (c *Config) IsLocal() bool {
+	return c.Env == EnvDevelopment || c.Env == EnvTest
+}
+
+// ==================== LOAD ====================
+
+// Load - baca konfigurasi dengan urutan prioritas: env > file > default, lalu validasi
+//
+// File (format KEY=VALUE seperti .env) dibaca dari CONFIG_FILE jika diisi (wajib ada),
+// selain itu dari .env jika file tersebut ada.
+func Load() (*Config, error) {
+	fileValues, err := readConfigFile()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := &Config{
+		RateLimits: make(map[string]string),
+		sources:    make(map[string]string),
+	}
+
+	lookup := func(name string) (string, string, bool) {
+		if value, ok := os.LookupEnv(name); ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value), "env", true
+		}
+		if value, ok := fileValues[name]; ok && strings.TrimSpace(value) != "" {
+			return strings.TrimSpace(value), "file", true
+		}
+		return "", "", false
+	}
+
+	var errs []error
+	forEachField(reflect.ValueOf(cfg).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		name := tag.Get("env")
+		value, source, ok := lookup(name)
+		if !ok {
+			value, source = tag.Get("default"), "default"
+		}
+		cfg.sources[name] = source
+
+		if err := setField(field, value); err != nil {
+			errs = append(errs, fmt.Errorf("invalid %s %q: %w", name, value, err))
+		}
+	})
+
+	for _, name := range rateLimitNames(fileValues) {
+		if value, source, ok := lookup(name); ok {
+			cfg.RateLimits[name] = value
+			cfg.sources[name] = source
+		}
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+
+	cfg.Env = strings.ToLower(cfg.Env)
+	cfg.Public.BaseURL = strings.TrimRight(cfg.Public.BaseURL, "/")
+
+	// Development / test tanpa secret: pakai secret acak (token tidak valid lagi setelah restart),
+	// env lain (staging, production) ditolak di Validate
+	if cfg.JWT.Secret == "" && cfg.IsLocal() {
+		cfg.JWT.Secret = randomSecret()
+		cfg.sources["SECRET_JWT_AUTOVERS"] = "generated"
+		log.Printf("WARNING: SECRET_JWT_AUTOVERS is not set, using a random secret (tokens are invalidated on restart)")
+	}
+
+	// Development / test tanpa key TOTP: pakai key acak (2FA yang diaktifkan tidak bisa dipakai lagi setelah restart)
+	if len(cfg.TOTP.EncryptionKeys) == 0 && cfg.IsLocal() {
+		cfg.TOTP.EncryptionKeys = []string{"dev:" + randomSecret()}
+		cfg.sources["TOTP_ENCRYPTION_KEY"] = "generated"
+		log.Printf("WARNING: TOTP_ENCRYPTION_KEY is not set, using a random key (two-factor secrets are unreadable after restart)")
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// readConfigFile - isi file konfigurasi (CONFIG_FILE atau .env), map kosong jika tidak ada
+func readConfigFile() (map[string]string, error) {
+	path := os.Getenv("CONFIG_FILE")
+	if path == "" {
+		if _, err := os.Stat(".env"); err != nil {
+			return map[string]string{}, nil
+		}
+		path = ".env"
+	}
+
+	values, err := godotenv.Read(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read config file %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// rateLimitNames - semua key RATE_LIMIT_* dari env dan file
+func rateLimitNames(fileValues map[string]string) []string {
+	seen := make(map[string]bool)
+	var names []string
+
+	add := func(name string) {
+		if strings.HasPrefix(name, "RATE_LIMIT_") && !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	for _, entry := range os.Environ() {
+		name, _, _ := strings.Cut(entry, "=")
+		add(name)
+	}
+	for name := range fileValues {
+		add(name)
+	}
+	return names
+}
+
+// forEachField - panggil fn untuk setiap field ber-tag `env` (termasuk di dalam struct nested)
+func forEachField(v reflect.Value, fn func(field reflect.Value, tag reflect.StructTag)) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, info := v.Field(i), t.Field(i)
+		if !info.IsExported() {
+			continue
+		}
+		if info.Type.Kind() == reflect.Struct {
+			forEachField(field, fn)
+			continue
+		}
+		if info.Tag.Get("env") != "" {
+			fn(field, info.Tag)
+		}
+	}
+}
+
+// setField - parse string ke tipe field (string, int, bool, time.Duration, []string)
+func setField(field reflect.Value, value string) error {
+	if field.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return errors.New("must be a duration (e.g. 30m, 24h)")
+		}
+		field.SetInt(int64(d))
+		return nil
+	}
+
+	switch field.Kind() {
+	case reflect.String:
+		field.SetString(value)
+	case reflect.Int:
+		if value == "" {
+			field.SetInt(0)
+			return nil
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return errors.New("must be an integer")
+		}
+		field.SetInt(int64(n))
+	case reflect.Bool:
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return errors.New("must be true or false")
+		}
+		field.SetBool(b)
+	case reflect.Slice:
+		var items []string
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config type %s", field.Type())
+	}
+	return nil
+}
+
+func randomSecret() string {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
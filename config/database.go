@@ -3,7 +3,6 @@ package config
 import (
 	"fmt"
 	"log"
-	"os"
 
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
@@ -11,23 +10,27 @@ import (
 
 var DB *gorm.DB
 
-func InitDatabase() {
+// InitDatabase - koneksi PostgreSQL dari DatabaseConfig (lihat Load)
+func InitDatabase(cfg DatabaseConfig) {
 	dsn := fmt.Sprintf(
-		"host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		os.Getenv("DB_HOST"),
-		os.Getenv("DB_USER"),
-		os.Getenv("DB_PASSWORD"),
-		os.Getenv("DB_NAME"),
-		os.Getenv("DB_PORT"),
-		os.Getenv("DB_SSLMODE"),
+		"host=%s user=%s password=%s dbname=%s port=%d sslmode=%s",
+		cfg.Host,
+		cfg.User,
+		cfg.Password,
+		cfg.Name,
+		cfg.Port,
+		cfg.SSLMode,
 	)
 
-	fmt.Println("Connecting to DB:", os.Getenv("DB_HOST"), os.Getenv("DB_NAME")) // debug
+	log.Printf("Connecting to database %s on %s:%d", cfg.Name, cfg.Host, cfg.Port)
 
 	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
 	if err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 
+	// Schema database dikelola lewat SQL migration (folder migrations), bukan AutoMigrate
+	// Jalankan: ./main migrate up
+
 	DB = db
 }
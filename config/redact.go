@@ -0,0 +1,55 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// redactedValue - pengganti nilai secret di dump konfigurasi
+const redactedValue = "********"
+
+// Setting - satu nilai konfigurasi untuk dump ops (secret sudah disensor)
+type Setting struct {
+	Key    string `json:"key"`
+	Value  string `json:"value"`
+	Source string `json:"source"` // default / file / env / generated
+}
+
+// Redacted - semua konfigurasi (urutan sesuai struct Config), nilai secret diganti "********"
+// Aman untuk ditampilkan di log, command `./main config` dan endpoint admin
+func (c *Config) Redacted() []Setting {
+	var settings []Setting
+
+	forEachField(reflect.ValueOf(c).Elem(), func(field reflect.Value, tag reflect.StructTag) {
+		name := tag.Get("env")
+		value := formatField(field)
+		if tag.Get("secret") == "true" && value != "" {
+			value = redactedValue
+		}
+		settings = append(settings, Setting{Key: name, Value: value, Source: c.sources[name]})
+	})
+
+	names := make([]string, 0, len(c.RateLimits))
+	for name := range c.RateLimits {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		settings = append(settings, Setting{Key: name, Value: c.RateLimits[name], Source: c.sources[name]})
+	}
+
+	return settings
+}
+
+func formatField(field reflect.Value) string {
+	if field.Kind() == reflect.Slice {
+		items := make([]string, field.Len())
+		for i := range items {
+			items[i] = fmt.Sprint(field.Index(i).Interface())
+		}
+		return strings.Join(items, ",")
+	}
+	return fmt.Sprint(field.Interface())
+}
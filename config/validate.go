@@ -0,0 +1,204 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+)
+
+// minJWTSecretLength - panjang minimal SECRET_JWT_AUTOVERS di staging / production (HS256 = 256 bit)
+const minJWTSecretLength = 32
+
+// insecureJWTSecrets - secret contoh / fallback lama yang tidak boleh dipakai di staging / production
+var insecureJWTSecrets = []string{"SECRET_JWT_AUTOVERS", "secret", "changeme", "jwt_secret"}
+
+// minEncryptionKeyLength - panjang minimal key di TOTP_ENCRYPTION_KEY (256 bit)
+const minEncryptionKeyLength = 32
+
+// encryptionKeyID - id key disimpan bersama data terenkripsi, jadi harus pendek dan tanpa ":"
+var encryptionKeyID = regexp.MustCompile(`^[A-Za-z0-9_-]{1,32}$`)
+
+// minJWTKeyGracePeriod - key lama harus tetap bisa verify minimal selama umur access token (utils.AccessTokenTTL)
+const minJWTKeyGracePeriod = time.Hour
+
+// Validate - cek field wajib dan nilai yang tidak masuk akal, semua kesalahan dikembalikan sekaligus
+func (c *Config) Validate() error {
+	var errs []error
+	fail := func(format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if !slices.Contains([]string{EnvDevelopment, EnvTest, EnvStaging, EnvProduction}, c.Env) {
+		fail("invalid APP_ENV %q (supported: development, test, staging, production)", c.Env)
+	}
+
+	if c.Server.Port < 1 || c.Server.Port > 65535 {
+		fail("invalid PORT %d", c.Server.Port)
+	}
+
+	// Tanpa daftar proxy, header IP bisa diisi sembarang client (bypass rate limit per IP)
+	if c.Server.ProxyHeader != "" && len(c.Server.TrustedProxies) == 0 {
+		fail("TRUSTED_PROXIES is required when PROXY_HEADER is set")
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if net.ParseIP(proxy) == nil {
+			if _, _, err := net.ParseCIDR(proxy); err != nil {
+				fail("invalid TRUSTED_PROXIES entry %q (expected an IP or CIDR)", proxy)
+			}
+		}
+	}
+
+	// Database
+	for _, field := range []struct{ name, value string }{
+		{"DB_HOST", c.Database.Host},
+		{"DB_USER", c.Database.User},
+		{"DB_NAME", c.Database.Name},
+	} {
+		if field.value == "" {
+			fail("%s is required", field.name)
+		}
+	}
+	if c.Database.Port < 1 || c.Database.Port > 65535 {
+		fail("invalid DB_PORT %d", c.Database.Port)
+	}
+	if !slices.Contains([]string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}, c.Database.SSLMode) {
+		fail("invalid DB_SSLMODE %q", c.Database.SSLMode)
+	}
+
+	// JWT secret, wajib di semua env kecuali development / test
+	if !c.IsLocal() {
+		switch {
+		case c.JWT.Secret == "":
+			fail("SECRET_JWT_AUTOVERS is required in %s", c.Env)
+		case slices.Contains(insecureJWTSecrets, c.JWT.Secret):
+			fail("SECRET_JWT_AUTOVERS uses a well-known insecure value, refusing to start in %s", c.Env)
+		case len(c.JWT.Secret) < minJWTSecretLength:
+			fail("SECRET_JWT_AUTOVERS must be at least %d characters in %s", minJWTSecretLength, c.Env)
+		}
+	}
+
+	switch c.JWT.Algorithm {
+	case "HS256", "RS256", "EdDSA":
+	default:
+		fail("unknown JWT_ALGORITHM %q (supported: EdDSA, RS256, HS256)", c.JWT.Algorithm)
+	}
+	if c.JWT.GracePeriod < minJWTKeyGracePeriod {
+		fail("JWT_KEY_GRACE_PERIOD must be at least %s", minJWTKeyGracePeriod)
+	}
+	if c.JWT.RotationInterval < c.JWT.GracePeriod {
+		fail("JWT_KEY_ROTATION_INTERVAL (%s) must not be shorter than JWT_KEY_GRACE_PERIOD (%s)", c.JWT.RotationInterval, c.JWT.GracePeriod)
+	}
+
+	// Mail
+	switch c.Mail.Transport {
+	case "smtp":
+		if c.Mail.SMTPHost == "" {
+			fail("SMTP_HOST is required when MAIL_TRANSPORT=smtp")
+		}
+		if c.Mail.SMTPPort < 1 || c.Mail.SMTPPort > 65535 {
+			fail("invalid SMTP_PORT %d", c.Mail.SMTPPort)
+		}
+		if c.Mail.From == "" {
+			fail("SMTP_FROM is required when MAIL_TRANSPORT=smtp")
+		}
+	case "file":
+	case "memory":
+		if c.IsProduction() {
+			fail("MAIL_TRANSPORT=memory discards all emails and is not allowed in production")
+		}
+	default:
+		fail("unknown MAIL_TRANSPORT %q (supported: smtp, file, memory)", c.Mail.Transport)
+	}
+
+	// Link di email
+	if err := c.Public.validate(c.IsProduction()); err != nil {
+		errs = append(errs, err)
+	}
+
+	// Password policy
+	if c.Password.MinLength < 1 {
+		fail("PASSWORD_MIN_LENGTH must be at least 1")
+	}
+	if c.Password.MaxBytes > 0 && c.Password.MinLength > c.Password.MaxBytes {
+		fail("PASSWORD_MIN_LENGTH (%d) must not exceed PASSWORD_MAX_BYTES (%d)", c.Password.MinLength, c.Password.MaxBytes)
+	}
+
+	if _, err := c.TOTP.Keys(); err != nil {
+		errs = append(errs, err)
+	}
+
+	if !slices.Contains([]string{"postgres", "memory"}, c.Security.LoginAttemptStore) {
+		fail("unknown LOGIN_ATTEMPT_STORE %q (supported: postgres, memory)", c.Security.LoginAttemptStore)
+	}
+
+	// Cookie auth (AllowCredentials) tidak boleh dipakai bersama origin wildcard
+	if len(c.CORS.AllowedOrigins) == 0 {
+		fail("ALLOWED_ORIGINS must contain at least one origin")
+	}
+	if slices.Contains(c.CORS.AllowedOrigins, "*") {
+		fail("ALLOWED_ORIGINS must not contain \"*\" (credentials are allowed)")
+	}
+
+	return errors.Join(errs...)
+}
+
+// validate - base URL harus absolut (http / https, wajib https di production), path harus diawali "/"
+func (p *PublicConfig) validate(production bool) error {
+	base, err := url.Parse(p.BaseURL)
+	if err != nil {
+		return fmt.Errorf("invalid PUBLIC_BASE_URL %q: %w", p.BaseURL, err)
+	}
+	if (base.Scheme != "http" && base.Scheme != "https") || base.Host == "" {
+		return fmt.Errorf("invalid PUBLIC_BASE_URL %q: must be an absolute http(s) URL", p.BaseURL)
+	}
+	if base.RawQuery != "" || base.Fragment != "" || base.User != nil {
+		return fmt.Errorf("invalid PUBLIC_BASE_URL %q: must not contain credentials, query or fragment", p.BaseURL)
+	}
+	if production && base.Scheme != "https" {
+		return fmt.Errorf("invalid PUBLIC_BASE_URL %q: must use https in production", p.BaseURL)
+	}
+
+	for _, field := range []struct{ name, path string }{
+		{"EMAIL_VERIFY_PATH", p.VerifyPath},
+		{"EMAIL_RESET_PASSWORD_PATH", p.ResetPasswordPath},
+		{"EMAIL_FORGOT_PASSWORD_PATH", p.ForgotPasswordPath},
+		{"EMAIL_UNLOCK_PATH", p.UnlockPath},
+	} {
+		if !strings.HasPrefix(field.path, "/") || strings.HasPrefix(field.path, "//") || strings.ContainsAny(field.path, "?# ") {
+			return fmt.Errorf("invalid %s %q: must be a path starting with \"/\" without query or fragment", field.name, field.path)
+		}
+	}
+	return nil
+}
+
+// Keys - parse TOTP_ENCRYPTION_KEY, key pertama adalah key aktif
+func (t TOTPConfig) Keys() ([]EncryptionKey, error) {
+	if len(t.EncryptionKeys) == 0 {
+		return nil, errors.New("TOTP_ENCRYPTION_KEY is required (format \"id:key\", comma separated, first key encrypts)")
+	}
+
+	keys := make([]EncryptionKey, 0, len(t.EncryptionKeys))
+	seen := make(map[string]bool)
+	for i, entry := range t.EncryptionKeys {
+		id, secret, _ := strings.Cut(entry, ":")
+		id, secret = strings.TrimSpace(id), strings.TrimSpace(secret)
+
+		switch {
+		case !encryptionKeyID.MatchString(id):
+			return nil, fmt.Errorf("invalid TOTP_ENCRYPTION_KEY entry %d: expected \"id:key\" with id of letters, digits, \"-\" or \"_\"", i+1)
+		case seen[id]:
+			return nil, fmt.Errorf("invalid TOTP_ENCRYPTION_KEY: duplicate key id %q", id)
+		case len(secret) < minEncryptionKeyLength:
+			return nil, fmt.Errorf("invalid TOTP_ENCRYPTION_KEY: key %q must be at least %d characters", id, minEncryptionKeyLength)
+		}
+
+		seen[id] = true
+		keys = append(keys, EncryptionKey{ID: id, Secret: secret})
+	}
+	return keys, nil
+}
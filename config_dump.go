@@ -0,0 +1,13 @@
+package main
+
+import (
+	"belajar-go-fiber/config"
+	"fmt"
+)
+
+// printConfig - subcommand `./main config`: tampilkan konfigurasi efektif (secret disensor) beserta asal nilainya
+func printConfig(cfg *config.Config) {
+	for _, setting := range cfg.Redacted() {
+		fmt.Printf("%-28s %-10s %s\n", setting.Key, "("+setting.Source+")", setting.Value)
+	}
+}
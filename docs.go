@@ -3,6 +3,11 @@ package main
 // @title Autovers API
 // @version 1.0
 // @description Autovers Backend API Documentation
+// @description
+// @description Every /auth endpoint below is also available under the /api/v1 prefix (for example /api/v1/auth/login); admin endpoints exist only under /api/v1. Responses under /api/v1 use a standard envelope:
+// @description success: {"data": <response>, "meta": {"request_id": "..."}}
+// @description error: {"error": {"code": "...", "message": "...", "details": {"field": ["..."]}}, "meta": {"request_id": "..."}}
+// @description The unprefixed /auth/* routes keep the legacy response shape documented here for compatibility. Every response carries an X-Request-ID header.
 // @termsOfService http://swagger.io/terms/
 
 // @contact.name API Support
@@ -11,10 +16,10 @@ package main
 // @license.name Apache 2.0
 // @license.url http://www.apache.org/licenses/LICENSE-2.0.html
 
-// @host localhost:8080
 // @BasePath /
 // @schemes http https
 
 // @securityDefinitions.apikey BearerAuth
-// @in cookie
-// @name auth_token
+// @in header
+// @name Authorization
+// @description Type "Bearer" followed by a space and the JWT (same value as the auth_token cookie set by /auth/login). Browsers can keep using the auth_token cookie; the Authorization header takes precedence when both are sent.
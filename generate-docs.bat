@@ -1,6 +1,6 @@
 @echo off
 REM Generate Swagger documentation for Windows
-swag init -g main.go
+swag init -g docs.go
 echo.
 echo ✅ Swagger documentation generated!
 echo.
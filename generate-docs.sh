@@ -1,5 +1,5 @@
 #!/bin/bash
 # Generate Swagger documentation
-swag init -g main.go
+swag init -g docs.go
 echo "✅ Swagger documentation generated!"
 echo "Visit: http://localhost:8080/swagger/index.html"
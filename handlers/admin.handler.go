@@ -0,0 +1,216 @@
+package handlers
+
+import (
+	"belajar-go-fiber/config"
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/services"
+	"belajar-go-fiber/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler - HTTP handler manajemen user, audit log dan konfigurasi (admin), service di-inject lewat NewAdminHandler
+type AdminHandler struct {
+	admin *services.AdminService
+	cfg   *config.Config
+}
+
+func NewAdminHandler(admin *services.AdminService, cfg *config.Config) *AdminHandler {
+	return &AdminHandler{admin: admin, cfg: cfg}
+}
+
+// @Summary List users
+// @Description List users with pagination, filter and search (admin only). Response is wrapped in the /api/v1 envelope
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number (default 1)"
+// @Param pageSize query int false "Page size (default 20, max 100)"
+// @Param search query string false "Search username, email or phone number"
+// @Param role query string false "Filter by role (user / admin)"
+// @Param active query string false "Filter by active status (true / false)"
+// @Param sort query string false "createdAt, userName or email, prefix - for descending (default -createdAt)"
+// @Success 200 {object} models.AdminUserListResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
+// @Router /api/v1/admin/users [get]
+// ListUsersHandler - HTTP handler untuk list user (admin)
+func (h *AdminHandler) ListUsersHandler(c *fiber.Ctx) error {
+	query := new(models.AdminUserQuery)
+	if err := c.QueryParser(query); err != nil {
+		return utils.JSONError(c, 400, "Invalid query parameters")
+	}
+
+	response, err := h.admin.ListUsers(*query)
+	if err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, response)
+}
+
+// @Summary Get user
+// @Description Get a single user (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.AdminUser
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/admin/users/{id} [get]
+// GetUserHandler - HTTP handler untuk detail user (admin)
+func (h *AdminHandler) GetUserHandler(c *fiber.Ctx) error {
+	user, err := h.admin.GetUser(c.Params("id"))
+	if err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, user)
+}
+
+// @Summary Activate user
+// @Description Reactivate a deactivated user account (admin only). Does not verify the email: an account whose email was never verified stays inactive until it is verified, see verify-email
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.AdminUser
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/admin/users/{id}/activate [post]
+// ActivateUserHandler - HTTP handler untuk mengaktifkan akun (admin)
+func (h *AdminHandler) ActivateUserHandler(c *fiber.Ctx) error {
+	return h.setUserActive(c, true)
+}
+
+// @Summary Deactivate user
+// @Description Deactivate a user account and sign it out of every device (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.AdminUser
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/admin/users/{id}/deactivate [post]
+// DeactivateUserHandler - HTTP handler untuk menonaktifkan akun (admin)
+func (h *AdminHandler) DeactivateUserHandler(c *fiber.Ctx) error {
+	return h.setUserActive(c, false)
+}
+
+// setUserActive - handler bersama untuk activate / deactivate
+func (h *AdminHandler) setUserActive(c *fiber.Ctx, active bool) error {
+	user, err := h.admin.SetUserActive(c.Params("id"), active, requestMeta(c))
+	if err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, user)
+}
+
+// @Summary Verify user email
+// @Description Mark the email of a user as verified without the verification link, recorded in the audit log (admin only). A deactivated account stays deactivated
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.AdminUser
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/admin/users/{id}/verify-email [post]
+// VerifyUserEmailHandler - HTTP handler untuk verifikasi email user (admin)
+func (h *AdminHandler) VerifyUserEmailHandler(c *fiber.Ctx) error {
+	user, err := h.admin.VerifyUserEmail(c.Params("id"), requestMeta(c))
+	if err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, user)
+}
+
+// @Summary Change user role
+// @Description Change the role of a user and sign it out of every device (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param request body models.UpdateUserRoleRequest true "New role"
+// @Success 200 {object} models.AdminUser
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
+// @Router /api/v1/admin/users/{id}/role [put]
+// ChangeUserRoleHandler - HTTP handler untuk ganti role user (admin)
+func (h *AdminHandler) ChangeUserRoleHandler(c *fiber.Ctx) error {
+	req := new(models.UpdateUserRoleRequest)
+	if err := c.BodyParser(req); err != nil {
+		return utils.JSONError(c, 400, "Invalid request")
+	}
+
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	user, err := h.admin.ChangeUserRole(c.Params("id"), req.Role, requestMeta(c))
+	if err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, user)
+}
+
+// @Summary Force password reset
+// @Description Invalidate the current password, sign the user out everywhere and email a reset password link (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Failure 409 {object} models.ErrorResponse
+// @Router /api/v1/admin/users/{id}/force-password-reset [post]
+// ForcePasswordResetHandler - HTTP handler untuk paksa reset password user (admin)
+func (h *AdminHandler) ForcePasswordResetHandler(c *fiber.Ctx) error {
+	if err := h.admin.ForcePasswordReset(c.Params("id"), requestMeta(c)); err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "Password reset link sent to the user",
+	})
+}
+
+// @Summary Delete user
+// @Description Permanently delete a user account with its sessions, tokens and failed login counter (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /api/v1/admin/users/{id} [delete]
+// DeleteUserHandler - HTTP handler untuk hapus akun user (admin)
+func (h *AdminHandler) DeleteUserHandler(c *fiber.Ctx) error {
+	if err := h.admin.DeleteUser(c.Params("id"), requestMeta(c)); err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "User deleted",
+	})
+}
@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/services"
+	"belajar-go-fiber/templates"
+	"belajar-go-fiber/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// requestMeta - ambil metadata request (user login, IP, user agent, request ID, bahasa) untuk audit trail dan email
+func requestMeta(c *fiber.Ctx) services.RequestMeta {
+	actorID, _ := c.Locals("userID").(string)
+	requestID, _ := c.Locals("requestID").(string)
+
+	var locale string
+	if c.Get(fiber.HeaderAcceptLanguage) != "" {
+		locale = c.AcceptsLanguages(templates.SupportedLocales...)
+	}
+
+	return services.RequestMeta{
+		ActorID:   actorID,
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+		RequestID: requestID,
+		Locale:    locale,
+	}
+}
+
+// @Summary List audit events
+// @Description List security-relevant events (login, register, verification, password reset, logout, admin actions), newest first (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Param page query int false "Page number (default 1)"
+// @Param pageSize query int false "Page size (default 20, max 100)"
+// @Param actorId query string false "Filter by actor user ID"
+// @Param targetId query string false "Filter by target user ID"
+// @Param action query string false "Exact action, or a prefix ending with * (e.g. auth.login.*)"
+// @Param from query string false "Start time, inclusive (RFC3339)"
+// @Param to query string false "End time, exclusive (RFC3339)"
+// @Success 200 {object} models.AuditEventListResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
+// @Router /api/v1/admin/audit-events [get]
+// ListAuditEventsHandler - HTTP handler untuk list audit event (admin)
+func (h *AdminHandler) ListAuditEventsHandler(c *fiber.Ctx) error {
+	query := new(models.AuditEventQuery)
+	if err := c.QueryParser(query); err != nil {
+		return utils.JSONError(c, 400, "Invalid query parameters")
+	}
+
+	response, err := h.admin.ListAuditEvents(*query)
+	if err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, response)
+}
@@ -9,6 +9,15 @@ import (
 	"github.com/gofiber/fiber/v2"
 )
 
+// AuthHandler - HTTP handler auth dan 2FA, service di-inject lewat NewAuthHandler
+type AuthHandler struct {
+	auth *services.AuthService
+}
+
+func NewAuthHandler(auth *services.AuthService) *AuthHandler {
+	return &AuthHandler{auth: auth}
+}
+
 // @Summary Register user
 // @Description Create a new user account
 // @Tags auth
@@ -17,64 +26,77 @@ import (
 // @Param request body models.RegisterRequest true "Register request"
 // @Success 201 {object} models.AuthResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
 // @Router /auth/register [post]
 // RegisterHandler - HTTP handler untuk registrasi
-func RegisterHandler(c *fiber.Ctx) error {
+func (h *AuthHandler) RegisterHandler(c *fiber.Ctx) error {
 	req := new(models.RegisterRequest)
 	if err := c.BodyParser(req); err != nil {
 		return utils.JSONError(c, 400, "Registration failed")
 	}
 
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
 	// Panggil service untuk logic bisnis
-	response, err := services.RegisterService(req)
+	response, err := h.auth.Register(req, requestMeta(c))
 	if err != nil {
-		return utils.JSONError(c, 400, err.Error())
+		return err
 	}
 
 	return utils.JSONSuccess(c, 201, response)
 }
 
 // @Summary Login user
-// @Description Login with email/username and password
+// @Description Login with email, username or phone number (08xx / +628xx) and password.
+// @Description If two-factor authentication is enabled, no cookie is set and the response contains mfaRequired=true and an mfaToken for /auth/login/2fa.
 // @Tags auth
 // @Accept json
 // @Produce json
 // @Param request body models.LoginRequest true "Login request"
 // @Success 200 {object} models.AuthResponse
 // @Failure 401 {object} models.ErrorResponse
+// @Failure 423 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
 // @Router /auth/login [post]
 // LoginHandler - HTTP handler untuk login
-func LoginHandler(c *fiber.Ctx) error {
+func (h *AuthHandler) LoginHandler(c *fiber.Ctx) error {
 	req := new(models.LoginRequest)
 	if err := c.BodyParser(req); err != nil {
 		return utils.JSONError(c, 400, "Login failed")
 	}
 
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
 	// Panggil service untuk logic bisnis
-	response, user, err := services.LoginService(req)
+	response, user, err := h.auth.Login(req, requestMeta(c))
 	if err != nil {
-		return utils.JSONError(c, 401, err.Error())
+		return err
 	}
 
-	// Set cookie dengan token JWT
-	loc, err := time.LoadLocation("Asia/Jakarta")
-	if err != nil {
-		// Fallback ke UTC jika timezone tidak tersedia
-		loc = time.UTC
+	// 2FA aktif → cookie baru diberikan setelah kode 2FA valid di /auth/login/2fa
+	if response.MFARequired {
+		return utils.JSONSuccess(c, 200, response)
 	}
-	
-	token, err := utils.GenerateToken(user.Email, user.UserName, user.Role)
+
+	return h.completeLogin(c, user, response)
+}
+
+// completeLogin - buat session baru, set cookie auth_token + refresh_token, lalu kirim response
+func (h *AuthHandler) completeLogin(c *fiber.Ctx, user *models.Users, response models.AuthResponse) error {
+	// Buat session baru + access token (JWT) dan refresh token
+	token, refreshToken, err := h.auth.StartSession(user, c.Get(fiber.HeaderUserAgent), c.IP())
 	if err != nil {
-		return utils.JSONError(c, 500, "Failed to generate token")
+		return err
 	}
 
-	c.Cookie(&fiber.Cookie{
-		Name:     "auth_token",
-		Value:    token,
-		HTTPOnly: true,
-		Secure:   false, // ubah ke true di production (https)
-		Expires:  time.Now().In(loc).Add(1 * time.Hour),
-	})
+	setAuthCookies(c, token, refreshToken)
 
 	return utils.JSONSuccess(c, 200, response)
 }
@@ -103,30 +125,30 @@ func MeHandler(c *fiber.Ctx) error {
 }
 
 // @Summary Verify email
-// @Description Verify user email with token from email link
+// @Description Verify user email with the one-time token from the latest verification email.
+// @Description Opening a link of an account that is already verified returns 200 with alreadyVerified=true.
 // @Tags auth
 // @Produce json
 // @Param token query string true "Verification token"
-// @Success 200 {object} models.MessageResponse
+// @Success 200 {object} models.VerifyEmailResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
 // @Router /auth/verify [get]
 // VerificationEmailHandler - HTTP handler untuk verifikasi email
-func VerificationEmailHandler(c *fiber.Ctx) error {
+func (h *AuthHandler) VerificationEmailHandler(c *fiber.Ctx) error {
 	token := c.Query("token")
 
 	// Panggil service untuk logic bisnis
-	err := services.VerifyEmailService(token)
+	response, err := h.auth.VerifyEmail(token, requestMeta(c))
 	if err != nil {
-		return utils.JSONError(c, 400, err.Error())
+		return err
 	}
 
-	return utils.JSONSuccess(c, 200, models.MessageResponse{
-		Message: "Email verified successfully",
-	})
+	return utils.JSONSuccess(c, 200, response)
 }
 
 // @Summary Logout user
-// @Description Clear authentication cookie
+// @Description Revoke current session and clear authentication cookies
 // @Tags auth
 // @Security BearerAuth
 // @Produce json
@@ -135,45 +157,119 @@ func VerificationEmailHandler(c *fiber.Ctx) error {
 // @Router /auth/logout [post]
 // LogoutHandler - HTTP handler untuk logout
 // ⭐ CATATAN: Route ini sudah di-protect oleh middleware, hanya user authenticated yang bisa logout
-func LogoutHandler(c *fiber.Ctx) error {
-	// Clear cookie "auth_token" dengan set MaxAge ke -1
-	c.Cookie(&fiber.Cookie{
-		Name:     "auth_token",
-		Value:    "",
-		HTTPOnly: true,
-		Secure:   false, // ubah ke true di production (https)
-		MaxAge:   -1,    // MaxAge: -1 untuk delete cookie
-	})
+func (h *AuthHandler) LogoutHandler(c *fiber.Ctx) error {
+	// Revoke session (auth_token dan refresh_token milik session ini langsung tidak berlaku)
+	sessionID, _ := c.Locals("sessionID").(string)
+	if err := h.auth.Logout(sessionID, requestMeta(c)); err != nil {
+		return err
+	}
+
+	clearAuthCookies(c)
 
 	return utils.JSONSuccess(c, 200, models.MessageResponse{
 		Message: "Logout successful",
 	})
 }
 
+// @Summary Resend verification email
+// @Description Send a new verification link to an unverified account. The response is the same whether or not the email is registered
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.EmailRequest true "Email address"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
+// @Router /auth/resend-verification [post]
+// ResendVerificationHandler - HTTP handler untuk kirim ulang email verifikasi
+func (h *AuthHandler) ResendVerificationHandler(c *fiber.Ctx) error {
+	req := new(models.EmailRequest)
+	if err := c.BodyParser(req); err != nil {
+		return utils.JSONError(c, 400, "Invalid request")
+	}
+
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	if err := h.auth.ResendVerification(req.Email); err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "If the email is registered and not yet verified, a new verification link has been sent",
+	})
+}
+
+// @Summary Unlock account
+// @Description Unlock an account locked after too many failed login attempts, using the token from the unlock email
+// @Tags auth
+// @Produce json
+// @Param token query string true "Unlock token"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Router /auth/unlock [get]
+// UnlockAccountHandler - HTTP handler untuk unlock akun dari link email
+func (h *AuthHandler) UnlockAccountHandler(c *fiber.Ctx) error {
+	if err := h.auth.UnlockAccount(c.Query("token"), requestMeta(c)); err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "Account unlocked, you can login again",
+	})
+}
+
+// @Summary Refresh access token
+// @Description Rotate refresh_token cookie and issue a new auth_token cookie
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.MessageResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /auth/refresh [post]
+// RefreshHandler - HTTP handler untuk rotasi refresh token
+func (h *AuthHandler) RefreshHandler(c *fiber.Ctx) error {
+	token, refreshToken, err := h.auth.RefreshSession(c.Cookies(refreshTokenCookie))
+	if err != nil {
+		clearAuthCookies(c)
+		return err
+	}
+
+	setAuthCookies(c, token, refreshToken)
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "Token refreshed",
+	})
+}
+
 // @Summary Request password reset
 // @Description Send reset password link to email
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param email body map[string]string true "Email address"
+// @Param request body models.EmailRequest true "Email address"
 // @Success 200 {object} models.MessageResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
 // @Router /auth/forgot-password [post]
 // ForgotPasswordHandler - HTTP handler untuk request reset password
-func ForgotPasswordHandler(c *fiber.Ctx) error {
-	type ForgotPasswordRequest struct {
-		Email string `json:"email"`
-	}
-
-	req := new(ForgotPasswordRequest)
+func (h *AuthHandler) ForgotPasswordHandler(c *fiber.Ctx) error {
+	req := new(models.EmailRequest)
 	if err := c.BodyParser(req); err != nil {
 		return utils.JSONError(c, 400, "Invalid request")
 	}
 
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
 	// Panggil service untuk generate token reset
-	err := services.ForgotPasswordService(req.Email)
+	err := h.auth.ForgotPassword(req.Email, requestMeta(c))
 	if err != nil {
-		return utils.JSONError(c, 400, err.Error())
+		return err
 	}
 
 	return utils.JSONSuccess(c, 200, models.MessageResponse{
@@ -182,34 +278,150 @@ func ForgotPasswordHandler(c *fiber.Ctx) error {
 }
 
 // @Summary Reset password
-// @Description Reset user password with reset token
+// @Description Reset user password with reset token. All sessions of the user are signed out
 // @Tags auth
 // @Accept json
 // @Produce json
-// @Param request body map[string]string true "Reset token and new password"
+// @Param request body models.ResetPasswordRequest true "Reset token and new password"
 // @Success 200 {object} models.MessageResponse
 // @Failure 400 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
 // @Router /auth/reset-password [post]
 // ResetPasswordHandler - HTTP handler untuk reset password dengan token
-func ResetPasswordHandler(c *fiber.Ctx) error {
-	type ResetPasswordRequest struct {
-		Token           string `json:"token"`
-		NewPassword     string `json:"newPassword"`
-		ConfirmPassword string `json:"confirmPassword"`
-	}
-
-	req := new(ResetPasswordRequest)
+func (h *AuthHandler) ResetPasswordHandler(c *fiber.Ctx) error {
+	req := new(models.ResetPasswordRequest)
 	if err := c.BodyParser(req); err != nil {
 		return utils.JSONError(c, 400, "Invalid request")
 	}
 
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
 	// Panggil service untuk reset password
-	err := services.ResetPasswordService(req.Token, req.NewPassword, req.ConfirmPassword)
+	err := h.auth.ResetPassword(req, requestMeta(c))
 	if err != nil {
-		return utils.JSONError(c, 400, err.Error())
+		return err
 	}
 
 	return utils.JSONSuccess(c, 200, models.MessageResponse{
 		Message: "Password reset successfully",
 	})
 }
+
+// ==================== COOKIE HELPERS ====================
+
+const (
+	authTokenCookie    = "auth_token"
+	refreshTokenCookie = "refresh_token"
+)
+
+// setAuthCookies - set cookie auth_token (JWT) dan refresh_token (opaque)
+func setAuthCookies(c *fiber.Ctx, accessToken, refreshToken string) {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		// Fallback ke UTC jika timezone tidak tersedia
+		loc = time.UTC
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     authTokenCookie,
+		Value:    accessToken,
+		HTTPOnly: true,
+		Secure:   false, // ubah ke true di production (https)
+		Expires:  time.Now().In(loc).Add(utils.AccessTokenTTL),
+	})
+
+	c.Cookie(&fiber.Cookie{
+		Name:     refreshTokenCookie,
+		Value:    refreshToken,
+		HTTPOnly: true,
+		Secure:   false, // ubah ke true di production (https)
+		Expires:  time.Now().In(loc).Add(utils.RefreshTokenTTL),
+	})
+}
+
+// clearAuthCookies - hapus cookie auth_token dan refresh_token
+func clearAuthCookies(c *fiber.Ctx) {
+	for _, name := range []string{authTokenCookie, refreshTokenCookie} {
+		c.Cookie(&fiber.Cookie{
+			Name:     name,
+			Value:    "",
+			HTTPOnly: true,
+			Secure:   false, // ubah ke true di production (https)
+			MaxAge:   -1,    // MaxAge: -1 untuk delete cookie
+		})
+	}
+}
+
+// @Summary Change password
+// @Description Change the password of the authenticated user. All other sessions are signed out
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.ChangePasswordRequest true "Current and new password"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
+// @Router /auth/change-password [post]
+// ChangePasswordHandler - HTTP handler untuk ganti password user yang sedang login
+func (h *AuthHandler) ChangePasswordHandler(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	sessionID := c.Locals("sessionID").(string)
+
+	req := new(models.ChangePasswordRequest)
+	if err := c.BodyParser(req); err != nil {
+		return utils.JSONError(c, 400, "Invalid request")
+	}
+
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	if err := h.auth.ChangePassword(userID, sessionID, req, requestMeta(c)); err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "Password changed successfully",
+	})
+}
+
+// @Summary Update email language
+// @Description Change the language used for emails sent to the authenticated user (en or id)
+// @Tags auth
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.UpdateLocaleRequest true "Preferred language"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
+// @Router /auth/me/locale [put]
+// UpdateLocaleHandler - HTTP handler untuk ganti bahasa email user yang sedang login
+func (h *AuthHandler) UpdateLocaleHandler(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	req := new(models.UpdateLocaleRequest)
+	if err := c.BodyParser(req); err != nil {
+		return utils.JSONError(c, 400, "Invalid request")
+	}
+
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	if err := h.auth.UpdateLocale(userID, req.Locale); err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "Language preference updated",
+	})
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Get configuration
+// @Description Effective configuration of this instance and where each value came from (default / file / env). Secrets are redacted (admin only)
+// @Tags admin
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.AdminConfigResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 403 {object} models.ErrorResponse
+// @Router /api/v1/admin/config [get]
+// GetConfigHandler - HTTP handler untuk dump konfigurasi tanpa secret (admin)
+func (h *AdminHandler) GetConfigHandler(c *fiber.Ctx) error {
+	response := models.AdminConfigResponse{Environment: h.cfg.Env}
+	for _, setting := range h.cfg.Redacted() {
+		response.Settings = append(response.Settings, models.ConfigSetting{
+			Key:    setting.Key,
+			Value:  setting.Value,
+			Source: setting.Source,
+		})
+	}
+
+	return utils.JSONSuccess(c, 200, response)
+}
@@ -0,0 +1,65 @@
+package handlers
+
+import (
+	"belajar-go-fiber/services"
+	"belajar-go-fiber/utils"
+	"errors"
+	"log"
+	"math"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errorKindStatus - mapping kategori error domain ke HTTP status
+var errorKindStatus = map[services.ErrorKind]int{
+	services.KindBadRequest:   fiber.StatusBadRequest,
+	services.KindValidation:   fiber.StatusUnprocessableEntity,
+	services.KindUnauthorized: fiber.StatusUnauthorized,
+	services.KindForbidden:    fiber.StatusForbidden,
+	services.KindNotFound:     fiber.StatusNotFound,
+	services.KindConflict:     fiber.StatusConflict,
+	services.KindLocked:       fiber.StatusLocked,
+	services.KindRateLimited:  fiber.StatusTooManyRequests,
+	services.KindInternal:     fiber.StatusInternalServerError,
+}
+
+// ErrorHandler - Fiber ErrorHandler global, dipasang di fiber.Config
+// Handler cukup `return err` dari service, status dan body error ditentukan di sini:
+// {"code": "email_already_registered", "message": "...", "details": {"field": ["..."]}}
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	// Error domain dari services
+	var appErr *services.AppError
+	if errors.As(err, &appErr) {
+		status, ok := errorKindStatus[appErr.Kind]
+		if !ok {
+			status = fiber.StatusInternalServerError
+		}
+
+		if status >= fiber.StatusInternalServerError && appErr.Err != nil {
+			log.Printf("%s %s: %s: %v", c.Method(), c.Path(), appErr.Code, appErr.Err)
+		}
+
+		if appErr.RetryAfter > 0 {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(appErr.RetryAfter.Seconds()))))
+		}
+
+		return utils.JSONErrorCode(c, status, appErr.Code, appErr.Message, appErr.Details)
+	}
+
+	// Error validasi input (utils.ValidateStruct)
+	var validationErr *utils.ValidationError
+	if errors.As(err, &validationErr) {
+		return utils.JSONValidationError(c, validationErr)
+	}
+
+	// Error bawaan Fiber (404 route tidak ada, body terlalu besar, dll)
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return utils.JSONError(c, fiberErr.Code, fiberErr.Message)
+	}
+
+	// Error tak terduga → jangan bocorkan detail ke client
+	log.Printf("%s %s: unhandled error: %v", c.Method(), c.Path(), err)
+	return utils.JSONError(c, fiber.StatusInternalServerError, "Internal server error")
+}
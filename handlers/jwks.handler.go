@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"belajar-go-fiber/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary JSON Web Key Set
+// @Description Public keys for verifying tokens issued by this service (RS256 / EdDSA, matched by the kid header).
+// @Description Keys are published before they are used for signing and stay listed during the rotation grace period.
+// @Description The same keys also sign short-lived MFA-pending tokens, so a service that accepts auth tokens MUST check,
+// @Description besides the signature and exp: header typ = "at+jwt", iss = "autovers-auth" and aud contains "autovers-api". Reject any token that fails one of these checks
+// @Tags auth
+// @Produce json
+// @Success 200 {object} models.JWKSResponse
+// @Router /.well-known/jwks.json [get]
+// JWKSHandler - HTTP handler untuk daftar public key JWT
+//
+// Verifier di service lain wajib cek (selain signature dan exp):
+//   - header typ = utils.AccessTokenType ("at+jwt")
+//   - iss = utils.JWTIssuer ("autovers-auth")
+//   - aud berisi utils.AccessTokenAudience ("autovers-api")
+//
+// Key yang sama juga men-sign mfaToken (typ / aud berbeda),
+// tanpa cek di atas mfaToken bisa dipakai sebagai auth_token (bypass 2FA)
+func JWKSHandler(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+	return c.JSON(utils.JWKS())
+}
@@ -0,0 +1,81 @@
+package handlers
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary List active sessions
+// @Description List devices where the authenticated user is currently logged in
+// @Tags sessions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {array} models.SessionInfo
+// @Failure 401 {object} models.ErrorResponse
+// @Router /auth/sessions [get]
+// ListSessionsHandler - HTTP handler untuk list session aktif user
+func (h *AuthHandler) ListSessionsHandler(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	sessionID := c.Locals("sessionID").(string)
+
+	sessions, err := h.auth.ListSessions(userID, sessionID)
+	if err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, sessions)
+}
+
+// @Summary Revoke a session
+// @Description Sign out a single device of the authenticated user
+// @Tags sessions
+// @Security BearerAuth
+// @Produce json
+// @Param id path string true "Session ID"
+// @Success 200 {object} models.MessageResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 404 {object} models.ErrorResponse
+// @Router /auth/sessions/{id} [delete]
+// RevokeSessionHandler - HTTP handler untuk revoke satu session
+func (h *AuthHandler) RevokeSessionHandler(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	currentSessionID := c.Locals("sessionID").(string)
+	sessionID := c.Params("id")
+
+	if err := h.auth.RevokeUserSession(userID, sessionID); err != nil {
+		return err
+	}
+
+	// Revoke session sendiri sama dengan logout
+	if sessionID == currentSessionID {
+		clearAuthCookies(c)
+	}
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "Session revoked",
+	})
+}
+
+// @Summary Sign out everywhere else
+// @Description Revoke every session of the authenticated user except the current one
+// @Tags sessions
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.MessageResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /auth/sessions [delete]
+// RevokeOtherSessionsHandler - HTTP handler untuk sign out dari semua device lain
+func (h *AuthHandler) RevokeOtherSessionsHandler(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+	sessionID := c.Locals("sessionID").(string)
+
+	if err := h.auth.RevokeAllSessions(userID, sessionID); err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "Signed out from all other sessions",
+	})
+}
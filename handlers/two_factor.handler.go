@@ -0,0 +1,133 @@
+package handlers
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// @Summary Start two-factor setup
+// @Description Generate a TOTP secret and otpauth URI for the authenticator app. 2FA is enabled only after /auth/2fa/confirm
+// @Tags two-factor
+// @Security BearerAuth
+// @Produce json
+// @Success 200 {object} models.TwoFactorSetupResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Router /auth/2fa/setup [post]
+// SetupTwoFactorHandler - HTTP handler untuk mulai enrollment 2FA
+func (h *AuthHandler) SetupTwoFactorHandler(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	response, err := h.auth.SetupTwoFactor(userID)
+	if err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, response)
+}
+
+// @Summary Confirm two-factor setup
+// @Description Enable 2FA with the first code from the authenticator app and receive one-time recovery codes
+// @Tags two-factor
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorCodeRequest true "TOTP code"
+// @Success 200 {object} models.RecoveryCodesResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
+// @Router /auth/2fa/confirm [post]
+// ConfirmTwoFactorHandler - HTTP handler untuk konfirmasi enrollment 2FA
+func (h *AuthHandler) ConfirmTwoFactorHandler(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	req := new(models.TwoFactorCodeRequest)
+	if err := c.BodyParser(req); err != nil {
+		return utils.JSONError(c, 400, "Invalid request")
+	}
+
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	codes, err := h.auth.ConfirmTwoFactor(userID, req.Code, requestMeta(c))
+	if err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, models.RecoveryCodesResponse{
+		Message:       "Two-factor authentication enabled, store these recovery codes safely",
+		RecoveryCodes: codes,
+	})
+}
+
+// @Summary Disable two-factor authentication
+// @Description Disable 2FA with the current password and a TOTP or recovery code
+// @Tags two-factor
+// @Security BearerAuth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorDisableRequest true "Password and 2FA code"
+// @Success 200 {object} models.MessageResponse
+// @Failure 400 {object} models.ErrorResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
+// @Router /auth/2fa/disable [post]
+// DisableTwoFactorHandler - HTTP handler untuk menonaktifkan 2FA
+func (h *AuthHandler) DisableTwoFactorHandler(c *fiber.Ctx) error {
+	userID := c.Locals("userID").(string)
+
+	req := new(models.TwoFactorDisableRequest)
+	if err := c.BodyParser(req); err != nil {
+		return utils.JSONError(c, 400, "Invalid request")
+	}
+
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	if err := h.auth.DisableTwoFactor(userID, req.Password, req.Code, requestMeta(c)); err != nil {
+		return err
+	}
+
+	return utils.JSONSuccess(c, 200, models.MessageResponse{
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+// @Summary Complete login with two-factor code
+// @Description Exchange the mfaToken from /auth/login and a TOTP or recovery code for the auth_token cookie. The mfaToken can only be used once
+// @Tags auth
+// @Accept json
+// @Produce json
+// @Param request body models.TwoFactorLoginRequest true "MFA token and code"
+// @Success 200 {object} models.AuthResponse
+// @Failure 401 {object} models.ErrorResponse
+// @Failure 423 {object} models.ErrorResponse
+// @Failure 429 {object} models.ErrorResponse
+// @Failure 422 {object} models.ValidationErrorResponse
+// @Router /auth/login/2fa [post]
+// TwoFactorLoginHandler - HTTP handler untuk langkah kedua login (2FA)
+func (h *AuthHandler) TwoFactorLoginHandler(c *fiber.Ctx) error {
+	req := new(models.TwoFactorLoginRequest)
+	if err := c.BodyParser(req); err != nil {
+		return utils.JSONError(c, 400, "Login failed")
+	}
+
+	// Validasi format input (tag `validate` di models)
+	if err := utils.ValidateStruct(req); err != nil {
+		return err
+	}
+
+	response, user, err := h.auth.CompleteTwoFactorLogin(req, requestMeta(c))
+	if err != nil {
+		return err
+	}
+
+	return h.completeLogin(c, user, response)
+}
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"belajar-go-fiber/config"
+	"belajar-go-fiber/repositories"
+	"belajar-go-fiber/services"
+	"fmt"
+	"log"
+	"os"
+	"time"
+)
+
+// runKeysCommand - subcommand `./main keys list|rotate`
+//
+//	list    tampilkan key JWT yang masih berlaku (pending / active / retiring)
+//	rotate  buat key baru sekarang tanpa menunggu JWT_KEY_ROTATION_INTERVAL
+//	        (key baru dipakai sign setelah dipublikasikan, key lama tetap bisa verify selama grace period)
+func runKeysCommand(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		keysUsage()
+	}
+
+	config.InitDatabase(cfg.Database)
+	repo := repositories.NewGormSigningKeyRepository(config.DB)
+	now := time.Now()
+
+	switch args[0] {
+	case "list":
+		keys, err := repo.ListUsable(now)
+		if err != nil {
+			log.Fatalf("Failed to load jwt keys: %v", err)
+		}
+
+		signing := ""
+		for _, key := range keys {
+			if !key.ActivatesAt.After(now) {
+				signing = key.ID
+				break
+			}
+		}
+
+		for _, key := range keys {
+			status := "retiring"
+			switch {
+			case key.ActivatesAt.After(now):
+				status = "pending"
+			case key.ID == signing:
+				status = "active"
+			}
+
+			expiresAt := "-"
+			if key.ExpiresAt != nil {
+				expiresAt = key.ExpiresAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%-26s %-6s %-9s activates %s  expires %s\n", key.ID, key.Algorithm, status, key.ActivatesAt.Format(time.RFC3339), expiresAt)
+		}
+
+	case "rotate":
+		manager, err := services.NewKeyManager(repo, cfg.JWT)
+		if err != nil {
+			log.Fatalf("Failed to configure jwt keys: %v", err)
+		}
+		if _, err := manager.Rotate(now, true); err != nil {
+			log.Fatalf("Rotation failed: %v", err)
+		}
+
+	default:
+		keysUsage()
+	}
+}
+
+func keysUsage() {
+	fmt.Fprintln(os.Stderr, "usage: main keys list | rotate")
+	os.Exit(2)
+}
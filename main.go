@@ -4,25 +4,70 @@ import (
 	"belajar-go-fiber/config"
 	"belajar-go-fiber/handlers"
 	"belajar-go-fiber/middlewares"
+	"belajar-go-fiber/repositories"
 	"belajar-go-fiber/routes"
+	"belajar-go-fiber/services"
+	"belajar-go-fiber/templates"
+	"belajar-go-fiber/utils"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"time"
 
 	_ "belajar-go-fiber/docs" // ⭐ Auto-generated docs
 
 	"github.com/gofiber/fiber/v2"
-	"github.com/joho/godotenv"
 	fiberSwagger "github.com/swaggo/fiber-swagger"
 )
 
 func main() {
-	app := fiber.New()
-	godotenv.Load()
+	// ⭐ CONFIG (env > CONFIG_FILE / .env > default, lihat config.Config), tolak start jika tidak valid
+	cfg, err := config.Load()
+	if err != nil {
+		log.Fatalf("Invalid configuration: %v", err)
+	}
+
+	// ⭐ SUBCOMMAND: ./main migrate up|down|status (lihat migrate.go) dan ./main config (dump konfigurasi tanpa secret)
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "migrate":
+			runMigrateCommand(cfg, os.Args[2:])
+			return
+		case "config":
+			printConfig(cfg)
+			return
+		case "keys":
+			runKeysCommand(cfg, os.Args[2:])
+			return
+		}
+	}
+
+	// ⭐ ErrorHandler global: handler cukup `return err`, status + body error ditentukan di handlers.ErrorHandler
+	// PROXY_HEADER + TRUSTED_PROXIES: c.IP() (rate limit dan brute-force per IP) = IP client asli di belakang proxy
+	app := fiber.New(fiber.Config{
+		ErrorHandler:            handlers.ErrorHandler,
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: len(cfg.Server.TrustedProxies) > 0,
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
+	})
 
 	// ⭐ CORS MIDDLEWARE (harus di awal, sebelum routes)
-	app.Use(middlewares.ConfigureCORS())
+	app.Use(middlewares.ConfigureCORS(cfg.CORS.AllowedOrigins))
+
+	// ⭐ RATE LIMIT OVERRIDE (RATE_LIMIT_<NAME>, harus sebelum routes didaftarkan)
+	middlewares.SetRateLimitOverrides(cfg.RateLimits)
+
+	// ⭐ REQUEST ID (header X-Request-ID, juga dipakai di meta.request_id response /api/v1)
+	app.Use(middlewares.RequestID())
 
 	// ⭐ SWAGGER DOCUMENTATION UI
 	app.Get("/swagger/*", fiberSwagger.WrapHandler)
 
+	// ⭐ JWKS (public key JWT untuk service lain, lihat services.KeyManager)
+	app.Get("/.well-known/jwks.json", handlers.JWKSHandler)
+
 	// ⭐ HEALTH CHECK / ROOT ENDPOINT
 	app.Get("/", func(c *fiber.Ctx) error {
 		return c.JSON(fiber.Map{
@@ -31,15 +76,81 @@ func main() {
 		})
 	})
 
-	// ⭐ INITIALIZE DATABASE
-	config.InitDatabase()
+	// ⭐ PASSWORD POLICY (PASSWORD_* dan BREACHED_PASSWORDS_FILE)
+	if err := services.LoadPasswordPolicy(cfg.Password); err != nil {
+		log.Fatalf("Failed to load password policy: %v", err)
+	}
+
+	// ⭐ EMAIL LINKS (PUBLIC_BASE_URL dan EMAIL_*_PATH, link di email harus mengarah ke frontend environment ini)
+	services.SetPublicConfig(cfg.Public)
+
+	// ⭐ EMAIL TEMPLATES (embedded, di-parse sekali supaya template rusak langsung ketahuan)
+	if err := templates.LoadEmailTemplates(); err != nil {
+		log.Fatalf("Failed to load email templates: %v", err)
+	}
+
+	// ⭐ INITIALIZE DATABASE (schema harus sudah di-migrate, lihat migrate.go)
+	config.InitDatabase(cfg.Database)
+	ensureSchemaUpToDate(cfg.Database.AutoMigrate)
+
+	// ⭐ JWT SIGNING KEYS (JWT_ALGORITHM, rotasi terjadwal + grace period, sinkron antar instance lewat database)
+	keyManager, err := services.NewKeyManager(repositories.NewGormSigningKeyRepository(config.DB), cfg.JWT)
+	if err != nil {
+		log.Fatalf("Failed to configure jwt keys: %v", err)
+	}
+	if err := keyManager.Sync(time.Now()); err != nil {
+		log.Fatalf("Failed to load jwt keys: %v", err)
+	}
+	go keyManager.Run(context.Background())
+
+	// ⭐ MAIL TRANSPORT (MAIL_TRANSPORT=smtp|file|memory, default smtp)
+	mailer, err := utils.NewMailer(cfg.Mail)
+	if err != nil {
+		log.Fatalf("Failed to configure mailer: %v", err)
+	}
+
+	// ⭐ EMAIL OUTBOX WORKER (kirim email di background dengan retry)
+	emailOutbox := repositories.NewGormEmailOutboxRepository(config.DB)
+	go services.NewEmailWorker(emailOutbox, mailer).Run(context.Background())
+
+	// ⭐ TOTP ENCRYPTION KEYS (TOTP_ENCRYPTION_KEY, terpisah dari secret JWT, key pertama untuk enkripsi)
+	totpKeyList, err := cfg.TOTP.Keys()
+	if err != nil {
+		log.Fatalf("Invalid totp encryption keys: %v", err)
+	}
+	totpKeys, err := utils.NewSecretKeyRing("autovers-totp-secret", totpKeyList)
+	if err != nil {
+		log.Fatalf("Failed to configure totp encryption keys: %v", err)
+	}
+
+	// ⭐ SERVICES & HANDLERS (repository GORM di-inject di sini)
+	// LOGIN_ATTEMPT_STORE=memory untuk counter brute-force protection di single instance / testing
+	authService := services.NewAuthService(services.AuthRepositories{
+		Users:         repositories.NewGormUserRepository(config.DB),
+		Audit:         repositories.NewGormAuditRepository(config.DB),
+		Outbox:        emailOutbox,
+		Sessions:      repositories.NewGormSessionRepository(config.DB),
+		RefreshTokens: repositories.NewGormRefreshTokenRepository(config.DB),
+		TwoFactor:     repositories.NewGormTwoFactorRepository(config.DB),
+		LoginAttempts: repositories.NewLoginAttemptStore(cfg.Security.LoginAttemptStore, config.DB),
+		Tx:            repositories.NewGormTransactor(config.DB),
+	}, totpKeys)
+	// ⭐ PURGE USER TOKENS (token email / mfaToken yang sudah lama expired dihapus berkala)
+	go authService.RunUserTokenPurge(context.Background())
+
+	authHandler := handlers.NewAuthHandler(authService)
+	adminHandler := handlers.NewAdminHandler(services.NewAdminService(authService), cfg)
+	protect := middlewares.ProtectRoute(authService)
 
-	// ⭐ PUBLIC ROUTES (tidak memerlukan authentication)
-	routes.AuthRoutes(app)
+	// ⭐ API v1 (format response envelope: data / error / meta)
+	v1 := app.Group("/api/v1", middlewares.ResponseEnvelope())
+	routes.AuthRoutes(v1, authHandler)
+	routes.ProtectedRoutes(v1, authHandler, protect)
+	routes.AdminRoutes(v1, adminHandler, protect)
 
-	// ⭐ PROTECTED ROUTES (hanya /auth/me dan /auth/logout yang perlu authentication)
-	app.Get("/auth/me", middlewares.ProtectRoute(), middlewares.RequireRole("admin", "user"), handlers.MeHandler)
-	app.Post("/auth/logout", middlewares.ProtectRoute(), middlewares.RequireRole("admin", "user"), handlers.LogoutHandler)
+	// ⭐ LEGACY ROUTES /auth/* (format response lama, compatibility untuk frontend yang sudah deploy)
+	routes.AuthRoutes(app, authHandler)
+	routes.ProtectedRoutes(app, authHandler, protect)
 
-	app.Listen("0.0.0.0:8080")
+	app.Listen(fmt.Sprintf("0.0.0.0:%d", cfg.Server.Port))
 }
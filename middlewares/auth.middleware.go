@@ -1,17 +1,24 @@
 package middlewares
 
 import (
+	"belajar-go-fiber/services"
 	"belajar-go-fiber/utils"
+	"errors"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // ProtectRoute - Middleware untuk protect route yang perlu authentication
-// Ambil token dari Cookie "auth_token" dan validasi
-func ProtectRoute() fiber.Handler {
+// Ambil token dari header "Authorization: Bearer <jwt>" atau Cookie "auth_token" dan validasi,
+// session dicek lewat auth (lihat services.AuthService.ValidateSession)
+func ProtectRoute(auth *services.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		// 1. Ambil token dari cookie "auth_token"
-		token := c.Cookies("auth_token")
+		// 1. Ambil token dari header Authorization atau cookie "auth_token"
+		token, ok := extractToken(c)
+		if !ok {
+			return utils.JSONError(c, fiber.StatusUnauthorized, "Unauthorized - Malformed Authorization header")
+		}
 
 		// 2. Jika tidak ada token
 		if token == "" {
@@ -24,31 +31,38 @@ func ProtectRoute() fiber.Handler {
 			return utils.JSONError(c, fiber.StatusUnauthorized, "Unauthorized - Invalid token")
 		}
 
-		// 4. Simpan user info ke context untuk digunakan di handler
-		c.Locals("email", claims.Email)
-		c.Locals("username", claims.UserName)
-		c.Locals("role", claims.Role)
+		// 4. Pastikan session belum di-revoke (logout / forced sign-out)
+		// Error database diteruskan ke ErrorHandler (500), bukan 401 yang membuat client membuang cookie
+		if err := auth.ValidateSession(claims.SessionID, claims.ID); err != nil {
+			if errors.Is(err, services.ErrSessionRevoked) {
+				return utils.JSONError(c, fiber.StatusUnauthorized, "Unauthorized - Session revoked")
+			}
+			return err
+		}
+
+		// 5. Simpan user info ke context untuk digunakan di handler
+		setAuthLocals(c, claims)
 
-		// 5. Lanjut ke handler berikutnya
+		// 6. Lanjut ke handler berikutnya
 		return c.Next()
 	}
 }
 
 // OptionalAuth - Middleware untuk optional authentication (user bisa ada atau tidak)
 // Jika ada token dan valid, simpan info ke context. Jika tidak ada, lanjutkan saja
-func OptionalAuth() fiber.Handler {
+func OptionalAuth(auth *services.AuthService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
-		token := c.Cookies("auth_token")
+		token, ok := extractToken(c)
 
 		// Jika ada token, coba validasi
-		if token != "" {
+		if ok && token != "" {
 			claims, err := utils.ParseToken(token)
-			if err == nil {
-				// Token valid, simpan ke context
-				c.Locals("email", claims.Email)
-				c.Locals("username", claims.UserName)
-				c.Locals("role", claims.Role)
+			if err == nil && auth.ValidateSession(claims.SessionID, claims.ID) == nil {
+				// Token valid dan session masih aktif, simpan ke context
+				setAuthLocals(c, claims)
 				c.Locals("isAuthenticated", true)
+			} else {
+				c.Locals("isAuthenticated", false)
 			}
 		} else {
 			// Tidak ada token, tandai belum authenticated
@@ -58,3 +72,35 @@ func OptionalAuth() fiber.Handler {
 		return c.Next()
 	}
 }
+
+// setAuthLocals - simpan info user dari claims ke context
+func setAuthLocals(c *fiber.Ctx, claims *utils.JwtClaims) {
+	c.Locals("userID", claims.Subject)
+	c.Locals("sessionID", claims.SessionID)
+	c.Locals("email", claims.Email)
+	c.Locals("username", claims.UserName)
+	c.Locals("role", claims.Role)
+}
+
+// extractToken - ambil JWT dari request
+// Urutan prioritas:
+//  1. Header "Authorization: Bearer <jwt>" (CLI, mobile app, server-to-server, Swagger UI)
+//  2. Cookie "auth_token" (browser)
+//
+// Jika header Authorization ada tapi formatnya salah, return ok=false (tidak fallback ke cookie)
+func extractToken(c *fiber.Ctx) (string, bool) {
+	if header := c.Get(fiber.HeaderAuthorization); header != "" {
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") {
+			return "", false
+		}
+
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return "", false
+		}
+		return token, true
+	}
+
+	return c.Cookies("auth_token"), true
+}
@@ -1,7 +1,6 @@
 package middlewares
 
 import (
-	"os"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -9,28 +8,13 @@ import (
 )
 
 // ConfigureCORS - Configure CORS middleware untuk allow frontend
-// Bisa di-set via environment variable ALLOWED_ORIGINS
-func ConfigureCORS() fiber.Handler {
-	// Get allowed origins dari environment variable atau default
-	allowedOriginsEnv := os.Getenv("ALLOWED_ORIGINS")
-
-	// Default allowed origins (development)
-	defaultOrigins := []string{
-		"http://localhost:3000",
-	}
-
-	var allowedOrigins string
-	if allowedOriginsEnv != "" {
-		allowedOrigins = allowedOriginsEnv
-	} else {
-		allowedOrigins = strings.Join(defaultOrigins, ",")
-	}
-
+// Origin yang diizinkan dari config ALLOWED_ORIGINS (default http://localhost:3000, lihat config.CORSConfig)
+func ConfigureCORS(allowedOrigins []string) fiber.Handler {
 	return cors.New(cors.Config{
-		AllowOrigins:     allowedOrigins, // Frontend domains yang boleh akses
+		AllowOrigins:     strings.Join(allowedOrigins, ","), // Frontend domains yang boleh akses
 		AllowMethods:     "GET,POST,PUT,DELETE,PATCH,OPTIONS",
-		AllowHeaders:     "Origin,Content-Type,Accept,Authorization",
-		ExposeHeaders:    "Content-Length,X-JSON-Response",
+		AllowHeaders:     "Origin,Content-Type,Accept,Authorization,X-Request-ID",
+		ExposeHeaders:    "Content-Length,X-JSON-Response,X-Request-ID,Retry-After",
 		AllowCredentials: true, // ⭐ PENTING untuk cookies/auth
 		MaxAge:           300,  // Pre-flight cache 5 menit
 	})
@@ -0,0 +1,215 @@
+package middlewares
+
+import (
+	"belajar-go-fiber/utils"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RateLimitKeyFunc - tentukan key bucket dari request (IP, email, user, dll)
+type RateLimitKeyFunc func(c *fiber.Ctx) string
+
+// RateLimitPolicy - policy token bucket untuk satu route
+// Limit request diizinkan per Period (burst maksimal = Limit, token terisi kembali rata sepanjang Period)
+// Bisa di-override via env RATE_LIMIT_<NAME>, contoh: RATE_LIMIT_REGISTER_IP="10/1h", atau "off" untuk menonaktifkan
+type RateLimitPolicy struct {
+	Name   string
+	Limit  int
+	Period time.Duration
+	KeyBy  RateLimitKeyFunc
+}
+
+// TokenBucketStore - penyimpanan bucket (default in-memory, bisa diganti Redis dll untuk multi instance)
+type TokenBucketStore interface {
+	// Take - ambil 1 token dari bucket key
+	// Return sisa token, waktu sampai 1 token tersedia (jika ditolak), dan waktu sampai bucket penuh lagi
+	Take(key string, limit int, period time.Duration, now time.Time) (allowed bool, remaining int, retryAfter, reset time.Duration)
+}
+
+var rateLimitStore TokenBucketStore = NewMemoryTokenBucketStore()
+
+// SetRateLimitStore - ganti store yang dipakai semua middleware RateLimit
+func SetRateLimitStore(store TokenBucketStore) {
+	rateLimitStore = store
+}
+
+// rateLimitOverrides - nilai RATE_LIMIT_<NAME> dari config (lihat config.Config.RateLimits)
+var rateLimitOverrides = map[string]string{}
+
+// SetRateLimitOverrides - set override policy, harus dipanggil sebelum routes didaftarkan
+func SetRateLimitOverrides(overrides map[string]string) {
+	rateLimitOverrides = overrides
+}
+
+// RateLimit - Middleware rate limit dengan token bucket
+// Cara pakai:
+//
+//	app.Post("/auth/register", RateLimit(RateLimitPolicy{Name: "register-ip", Limit: 5, Period: time.Hour, KeyBy: KeyByIP}), handler)
+//
+// Response selalu berisi header RateLimit-Limit, RateLimit-Remaining, RateLimit-Reset,
+// dan Retry-After jika request ditolak (HTTP 429)
+func RateLimit(policy RateLimitPolicy) fiber.Handler {
+	policy, enabled := applyRateLimitOverride(policy)
+	if !enabled {
+		return func(c *fiber.Ctx) error {
+			return c.Next()
+		}
+	}
+
+	if policy.KeyBy == nil {
+		policy.KeyBy = KeyByIP
+	}
+
+	return func(c *fiber.Ctx) error {
+		key := policy.Name + ":" + policy.KeyBy(c)
+
+		allowed, remaining, retryAfter, reset := rateLimitStore.Take(key, policy.Limit, policy.Period, time.Now())
+
+		c.Set("RateLimit-Limit", strconv.Itoa(policy.Limit))
+		c.Set("RateLimit-Remaining", strconv.Itoa(remaining))
+		c.Set("RateLimit-Reset", strconv.Itoa(ceilSeconds(reset)))
+
+		if !allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(retryAfter)))
+			return utils.JSONError(c, fiber.StatusTooManyRequests, "Too many requests, please try again later")
+		}
+
+		return c.Next()
+	}
+}
+
+// KeyByIP - bucket per IP address
+func KeyByIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// KeyByEmail - bucket per email di body JSON ("email" atau "identifier"), fallback ke IP
+func KeyByEmail(c *fiber.Ctx) string {
+	var body struct {
+		Email      string `json:"email"`
+		Identifier string `json:"identifier"`
+	}
+	_ = c.BodyParser(&body)
+
+	email := body.Email
+	if email == "" {
+		email = body.Identifier
+	}
+
+	email = strings.ToLower(strings.TrimSpace(email))
+	if email == "" {
+		return KeyByIP(c)
+	}
+	return "email:" + email
+}
+
+// KeyByUser - bucket per user yang login (dipasang setelah ProtectRoute), fallback ke IP
+func KeyByUser(c *fiber.Ctx) string {
+	if userID, ok := c.Locals("userID").(string); ok && userID != "" {
+		return "user:" + userID
+	}
+	return KeyByIP(c)
+}
+
+// applyRateLimitOverride - override Limit/Period dari config RATE_LIMIT_<NAME> ("N/durasi" atau "off")
+func applyRateLimitOverride(policy RateLimitPolicy) (RateLimitPolicy, bool) {
+	envName := "RATE_LIMIT_" + strings.ToUpper(strings.NewReplacer("-", "_", "/", "_").Replace(policy.Name))
+
+	value := strings.TrimSpace(rateLimitOverrides[envName])
+	if value == "" {
+		return policy, true
+	}
+	if strings.EqualFold(value, "off") {
+		return policy, false
+	}
+
+	limit, period, found := strings.Cut(value, "/")
+	n, errLimit := strconv.Atoi(strings.TrimSpace(limit))
+	d, errPeriod := time.ParseDuration(strings.TrimSpace(period))
+	if !found || errLimit != nil || errPeriod != nil || n <= 0 || d <= 0 {
+		log.Printf("invalid %s=%q (expected e.g. \"5/1m\"), using default %d/%s", envName, value, policy.Limit, policy.Period)
+		return policy, true
+	}
+
+	policy.Limit = n
+	policy.Period = d
+	return policy, true
+}
+
+// ceilSeconds - durasi dibulatkan ke atas dalam detik (untuk header)
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// ==================== MEMORY TOKEN BUCKET STORE ====================
+
+// MemoryTokenBucketStore - token bucket di memory (per instance)
+type MemoryTokenBucketStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens   float64
+	last     time.Time
+	fullTime time.Duration // waktu untuk mengisi bucket dari kosong sampai penuh
+}
+
+func NewMemoryTokenBucketStore() *MemoryTokenBucketStore {
+	return &MemoryTokenBucketStore{buckets: make(map[string]*tokenBucket)}
+}
+
+func (s *MemoryTokenBucketStore) Take(key string, limit int, period time.Duration, now time.Time) (bool, int, time.Duration, time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep(now)
+
+	rate := float64(limit) / period.Seconds() // token per detik
+
+	bucket, ok := s.buckets[key]
+	if !ok {
+		bucket = &tokenBucket{tokens: float64(limit), last: now, fullTime: period}
+		s.buckets[key] = bucket
+	}
+
+	// Isi ulang token sesuai waktu yang sudah lewat
+	elapsed := now.Sub(bucket.last).Seconds()
+	bucket.tokens = math.Min(float64(limit), bucket.tokens+elapsed*rate)
+	bucket.last = now
+
+	allowed := bucket.tokens >= 1
+	if allowed {
+		bucket.tokens--
+	}
+
+	reset := time.Duration((float64(limit) - bucket.tokens) / rate * float64(time.Second))
+
+	var retryAfter time.Duration
+	if !allowed {
+		retryAfter = time.Duration((1 - bucket.tokens) / rate * float64(time.Second))
+	}
+
+	return allowed, int(bucket.tokens), retryAfter, reset
+}
+
+// sweep - hapus bucket yang sudah penuh kembali supaya memory tidak terus bertambah
+func (s *MemoryTokenBucketStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < time.Minute {
+		return
+	}
+	s.lastSweep = now
+
+	for key, bucket := range s.buckets {
+		if now.Sub(bucket.last) > bucket.fullTime {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package middlewares
+
+import (
+	"io"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TestMemoryTokenBucketStoreRefillsOverPeriod(t *testing.T) {
+	store := NewMemoryTokenBucketStore()
+	now := time.Now()
+
+	// Burst sampai Limit, lalu ditolak
+	for i := 0; i < 3; i++ {
+		allowed, remaining, _, _ := store.Take("key", 3, time.Minute, now)
+		if !allowed || remaining != 2-i {
+			t.Fatalf("take %d = %v, remaining %d", i+1, allowed, remaining)
+		}
+	}
+
+	allowed, _, retryAfter, reset := store.Take("key", 3, time.Minute, now)
+	if allowed {
+		t.Fatal("request allowed after bucket is empty")
+	}
+	if retryAfter != 20*time.Second || reset != time.Minute {
+		t.Fatalf("retryAfter = %s, reset = %s, want 20s and 1m", retryAfter, reset)
+	}
+
+	// 1 token terisi kembali setiap Period/Limit
+	if allowed, _, _, _ := store.Take("key", 3, time.Minute, now.Add(20*time.Second)); !allowed {
+		t.Fatal("request rejected after one token refilled")
+	}
+
+	// Key lain punya bucket sendiri
+	if allowed, _, _, _ := store.Take("other", 3, time.Minute, now); !allowed {
+		t.Fatal("request for other key rejected")
+	}
+}
+
+func TestRateLimitSetsHeaders(t *testing.T) {
+	SetRateLimitStore(NewMemoryTokenBucketStore())
+	t.Cleanup(func() { SetRateLimitStore(NewMemoryTokenBucketStore()) })
+
+	app := fiber.New()
+	app.Get("/", RateLimit(RateLimitPolicy{Name: "test", Limit: 2, Period: time.Minute}), func(c *fiber.Ctx) error {
+		return c.SendStatus(fiber.StatusNoContent)
+	})
+
+	tests := []struct {
+		status     int
+		remaining  string
+		retryAfter string
+	}{
+		{fiber.StatusNoContent, "1", ""},
+		{fiber.StatusNoContent, "0", ""},
+		{fiber.StatusTooManyRequests, "0", "30"},
+	}
+
+	for i, tt := range tests {
+		resp, err := app.Test(httptest.NewRequest(fiber.MethodGet, "/", nil))
+		if err != nil {
+			t.Fatalf("request %d: %v", i+1, err)
+		}
+
+		if resp.StatusCode != tt.status {
+			t.Fatalf("request %d: status = %d, want %d", i+1, resp.StatusCode, tt.status)
+		}
+		if got := resp.Header.Get("RateLimit-Limit"); got != "2" {
+			t.Fatalf("request %d: RateLimit-Limit = %q, want 2", i+1, got)
+		}
+		if got := resp.Header.Get("RateLimit-Remaining"); got != tt.remaining {
+			t.Fatalf("request %d: RateLimit-Remaining = %q, want %s", i+1, got, tt.remaining)
+		}
+		if got := resp.Header.Get(fiber.HeaderRetryAfter); got != tt.retryAfter {
+			t.Fatalf("request %d: Retry-After = %q, want %q", i+1, got, tt.retryAfter)
+		}
+	}
+}
+
+func TestRateLimitOverride(t *testing.T) {
+	tests := []struct {
+		value   string
+		enabled bool
+		limit   int
+		period  time.Duration
+	}{
+		{"", true, 5, time.Hour},
+		{"off", false, 5, time.Hour},
+		{"10/1m", true, 10, time.Minute},
+		{"invalid", true, 5, time.Hour},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			SetRateLimitOverrides(map[string]string{"RATE_LIMIT_REGISTER_IP": tt.value})
+			t.Cleanup(func() { SetRateLimitOverrides(map[string]string{}) })
+
+			policy, enabled := applyRateLimitOverride(RateLimitPolicy{Name: "register-ip", Limit: 5, Period: time.Hour})
+			if enabled != tt.enabled || policy.Limit != tt.limit || policy.Period != tt.period {
+				t.Fatalf("policy = %d/%s enabled %v, want %d/%s enabled %v", policy.Limit, policy.Period, enabled, tt.limit, tt.period, tt.enabled)
+			}
+		})
+	}
+}
+
+func TestKeyByIPUsesProxyHeaderOnlyFromTrustedProxy(t *testing.T) {
+	tests := []struct {
+		name    string
+		trusted []string
+		want    string
+	}{
+		// app.Test memakai koneksi dari 0.0.0.0
+		{"trusted proxy", []string{"0.0.0.0"}, "ip:203.0.113.7"},
+		{"untrusted proxy", []string{"10.0.0.1"}, "ip:0.0.0.0"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := fiber.New(fiber.Config{
+				ProxyHeader:             "X-Real-IP",
+				EnableTrustedProxyCheck: true,
+				TrustedProxies:          tt.trusted,
+				EnableIPValidation:      true,
+			})
+			app.Get("/", func(c *fiber.Ctx) error {
+				return c.SendString(KeyByIP(c))
+			})
+
+			req := httptest.NewRequest(fiber.MethodGet, "/", nil)
+			req.Header.Set("X-Real-IP", "203.0.113.7")
+			resp, err := app.Test(req)
+			if err != nil {
+				t.Fatalf("request: %v", err)
+			}
+
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.want {
+				t.Fatalf("key = %q, want %q", body, tt.want)
+			}
+		})
+	}
+}
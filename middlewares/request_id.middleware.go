@@ -0,0 +1,47 @@
+package middlewares
+
+import (
+	"belajar-go-fiber/utils"
+	"regexp"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HeaderRequestID - header untuk korelasi request (dikirim balik di setiap response)
+const HeaderRequestID = "X-Request-ID"
+
+// requestIDPattern - request ID dari client hanya dipakai jika formatnya aman untuk log
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9._-]{8,128}$`)
+
+// RequestID - Middleware untuk memberi setiap request sebuah ID
+// Pakai X-Request-ID dari client (misal dari load balancer) jika valid, selain itu generate baru.
+// ID disimpan di c.Locals("requestID") dan dikirim balik di header X-Request-ID
+func RequestID() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		requestID := c.Get(HeaderRequestID)
+		if !requestIDPattern.MatchString(requestID) {
+			generated, err := utils.GenerateRandomToken(16)
+			if err != nil {
+				return err
+			}
+			requestID = generated
+		}
+
+		c.Locals("requestID", requestID)
+		c.Set(HeaderRequestID, requestID)
+
+		return c.Next()
+	}
+}
+
+// ResponseEnvelope - Middleware untuk route /api/v1
+// Semua response (utils.JSONSuccess / utils.JSONError / ErrorHandler) dibungkus format standar:
+//
+//	{"data": ..., "meta": {"request_id": "..."}}
+//	{"error": {"code": "...", "message": "...", "details": {...}}, "meta": {"request_id": "..."}}
+func ResponseEnvelope() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		utils.UseEnvelope(c)
+		return c.Next()
+	}
+}
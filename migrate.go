@@ -0,0 +1,118 @@
+package main
+
+import (
+	"belajar-go-fiber/config"
+	"belajar-go-fiber/migrations"
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+)
+
+// runMigrateCommand - subcommand `./main migrate up|down [N]|status`
+//
+//	up       jalankan semua migration yang belum dijalankan
+//	down [N] rollback N migration terakhir (default 1)
+//	status   tampilkan migration yang sudah / belum dijalankan
+func runMigrateCommand(cfg *config.Config, args []string) {
+	if len(args) == 0 {
+		migrateUsage()
+	}
+
+	config.InitDatabase(cfg.Database)
+	db := sqlDB()
+	ctx := context.Background()
+
+	switch args[0] {
+	case "up":
+		applied, err := migrations.Up(ctx, db)
+		for _, migration := range applied {
+			fmt.Printf("applied  %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		if len(applied) == 0 {
+			fmt.Println("database is up to date")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				migrateUsage()
+			}
+			steps = n
+		}
+
+		reverted, err := migrations.Down(ctx, db, steps)
+		for _, migration := range reverted {
+			fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			log.Fatalf("Rollback failed: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("nothing to roll back")
+		}
+
+	case "status":
+		list, err := migrations.List(ctx, db)
+		if err != nil {
+			log.Fatalf("Failed to read migration status: %v", err)
+		}
+		for _, status := range list {
+			appliedAt := "pending"
+			if status.AppliedAt != nil {
+				appliedAt = "applied " + status.AppliedAt.Format("2006-01-02 15:04:05 MST")
+			}
+			fmt.Printf("%04d_%-40s %s\n", status.Version, status.Name, appliedAt)
+		}
+
+	default:
+		migrateUsage()
+	}
+}
+
+// ensureSchemaUpToDate - dipanggil saat server start
+// DB_AUTO_MIGRATE=true → jalankan migration otomatis, selain itu tolak start jika masih ada migration pending
+func ensureSchemaUpToDate(autoMigrate bool) {
+	db := sqlDB()
+	ctx := context.Background()
+
+	if autoMigrate {
+		applied, err := migrations.Up(ctx, db)
+		if err != nil {
+			log.Fatalf("Failed to migrate database: %v", err)
+		}
+		for _, migration := range applied {
+			log.Printf("Applied migration %04d_%s", migration.Version, migration.Name)
+		}
+		return
+	}
+
+	pending, err := migrations.Pending(ctx, db)
+	if err != nil {
+		log.Fatalf("Failed to read migration status: %v", err)
+	}
+	if pending > 0 {
+		log.Fatalf("Database has %d pending migration(s), run `./main migrate up` or set DB_AUTO_MIGRATE=true", pending)
+	}
+}
+
+// sqlDB - koneksi database/sql di balik config.DB (GORM)
+func sqlDB() *sql.DB {
+	db, err := config.DB.DB()
+	if err != nil {
+		log.Fatalf("Failed to get database connection: %v", err)
+	}
+	return db
+}
+
+func migrateUsage() {
+	fmt.Fprintln(os.Stderr, "usage: main migrate up | down [N] | status")
+	os.Exit(2)
+}
@@ -0,0 +1,285 @@
+package migrations
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// File migration ada di migrations/sql dan ikut di-embed ke binary
+// Format nama file: <versi>_<nama>.up.sql dan <versi>_<nama>.down.sql, contoh 0002_create_users.up.sql
+// Migration yang sudah dijalankan di production tidak boleh diubah, buat file baru dengan versi berikutnya
+
+//go:embed sql/*.sql
+var sqlFiles embed.FS
+
+// advisoryLockID - kunci pg_advisory_lock supaya dua proses tidak menjalankan migration bersamaan
+const advisoryLockID = 7240615
+
+// Migration - satu versi schema
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+// Status - status satu migration di database
+type Status struct {
+	Migration
+	AppliedAt *time.Time // nil jika belum dijalankan
+}
+
+// Load - baca semua migration dari file embed, urut berdasarkan versi
+func Load() ([]Migration, error) {
+	entries, err := fs.ReadDir(sqlFiles, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+
+		base, direction, ok := cutDirection(fileName)
+		if !ok {
+			return nil, fmt.Errorf("migration %s: file name must end with .up.sql or .down.sql", fileName)
+		}
+
+		versionText, name, ok := strings.Cut(base, "_")
+		version, err := strconv.ParseInt(versionText, 10, 64)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration %s: file name must start with a positive version number followed by \"_\"", fileName)
+		}
+
+		content, err := sqlFiles.ReadFile("sql/" + fileName)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, exists := byVersion[version]
+		if !exists {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		}
+		if migration.Name != name {
+			return nil, fmt.Errorf("migration version %d is used by %q and %q", version, migration.Name, name)
+		}
+
+		if direction == "up" {
+			migration.Up = string(content)
+		} else {
+			migration.Down = string(content)
+		}
+	}
+
+	result := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.Up == "" || migration.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s must have both .up.sql and .down.sql", migration.Version, migration.Name)
+		}
+		result = append(result, *migration)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Version < result[j].Version })
+
+	return result, nil
+}
+
+// cutDirection - pisahkan "0001_name.up.sql" menjadi ("0001_name", "up")
+func cutDirection(fileName string) (string, string, bool) {
+	if base, ok := strings.CutSuffix(fileName, ".up.sql"); ok {
+		return base, "up", true
+	}
+	if base, ok := strings.CutSuffix(fileName, ".down.sql"); ok {
+		return base, "down", true
+	}
+	return "", "", false
+}
+
+// Up - jalankan semua migration yang belum dijalankan, return migration yang baru dijalankan
+func Up(ctx context.Context, db *sql.DB) ([]Migration, error) {
+	var applied []Migration
+
+	err := withLock(ctx, db, func(conn *sql.Conn) error {
+		statuses, err := statuses(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for _, status := range statuses {
+			if status.AppliedAt != nil {
+				continue
+			}
+
+			if err := apply(ctx, conn, status.Migration, status.Up, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx,
+					`INSERT INTO schema_migrations (version, name, "appliedAt") VALUES ($1, $2, now())`,
+					status.Version, status.Name)
+				return err
+			}); err != nil {
+				return err
+			}
+			applied = append(applied, status.Migration)
+		}
+		return nil
+	})
+
+	return applied, err
+}
+
+// Down - rollback `steps` migration terakhir yang sudah dijalankan, return migration yang di-rollback
+func Down(ctx context.Context, db *sql.DB, steps int) ([]Migration, error) {
+	var reverted []Migration
+
+	err := withLock(ctx, db, func(conn *sql.Conn) error {
+		statuses, err := statuses(ctx, conn)
+		if err != nil {
+			return err
+		}
+
+		for i := len(statuses) - 1; i >= 0 && len(reverted) < steps; i-- {
+			status := statuses[i]
+			if status.AppliedAt == nil {
+				continue
+			}
+
+			if err := apply(ctx, conn, status.Migration, status.Down, func(tx *sql.Tx) error {
+				_, err := tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = $1`, status.Version)
+				return err
+			}); err != nil {
+				return err
+			}
+			reverted = append(reverted, status.Migration)
+		}
+		return nil
+	})
+
+	return reverted, err
+}
+
+// List - status semua migration (sudah / belum dijalankan)
+func List(ctx context.Context, db *sql.DB) ([]Status, error) {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return nil, err
+	}
+	return statuses(ctx, conn)
+}
+
+// Pending - jumlah migration yang belum dijalankan
+func Pending(ctx context.Context, db *sql.DB) (int, error) {
+	list, err := List(ctx, db)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := 0
+	for _, status := range list {
+		if status.AppliedAt == nil {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+// withLock - jalankan fn dengan advisory lock di satu koneksi
+func withLock(ctx context.Context, db *sql.DB, fn func(conn *sql.Conn) error) error {
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_lock($1)`, advisoryLockID); err != nil {
+		return fmt.Errorf("acquire migration lock: %w", err)
+	}
+	defer conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, advisoryLockID)
+
+	if err := ensureTable(ctx, conn); err != nil {
+		return err
+	}
+	return fn(conn)
+}
+
+// ensureTable - buat tabel schema_migrations jika belum ada
+func ensureTable(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS schema_migrations (
+		version     bigint PRIMARY KEY,
+		name        text NOT NULL,
+		"appliedAt" timestamptz NOT NULL
+	)`)
+	return err
+}
+
+// statuses - gabungkan migration dari file embed dengan versi yang tercatat di schema_migrations
+func statuses(ctx context.Context, conn *sql.Conn) ([]Status, error) {
+	migrations, err := Load()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := conn.QueryContext(ctx, `SELECT version, "appliedAt" FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	appliedAt := make(map[int64]time.Time)
+	for rows.Next() {
+		var version int64
+		var at time.Time
+		if err := rows.Scan(&version, &at); err != nil {
+			return nil, err
+		}
+		appliedAt[version] = at
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]Status, 0, len(migrations))
+	for _, migration := range migrations {
+		status := Status{Migration: migration}
+		if at, ok := appliedAt[migration.Version]; ok {
+			status.AppliedAt = &at
+			delete(appliedAt, migration.Version)
+		}
+		result = append(result, status)
+	}
+
+	// Versi di database yang tidak ada file-nya → binary lebih lama dari schema
+	for version := range appliedAt {
+		return nil, fmt.Errorf("database has migration version %d that is unknown to this binary", version)
+	}
+
+	return result, nil
+}
+
+// apply - jalankan SQL migration dan update schema_migrations dalam satu transaksi
+func apply(ctx context.Context, conn *sql.Conn, migration Migration, statements string, record func(tx *sql.Tx) error) error {
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, statements); err != nil {
+		return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if err := record(tx); err != nil {
+		return fmt.Errorf("migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1 @@
+DROP FUNCTION IF EXISTS generate_object_id();
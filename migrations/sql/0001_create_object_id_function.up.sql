@@ -0,0 +1,7 @@
+-- ID default semua tabel: 24 karakter hex seperti MongoDB ObjectId
+-- (8 hex detik epoch + 16 hex random), jadi ID tetap terurut kira-kira sesuai waktu dibuat.
+-- gen_random_uuid() bawaan PostgreSQL 13+, tidak perlu extension pgcrypto.
+CREATE OR REPLACE FUNCTION generate_object_id() RETURNS text AS $$
+    SELECT lpad(to_hex(floor(extract(epoch FROM clock_timestamp()))::bigint), 8, '0')
+        || substr(replace(gen_random_uuid()::text, '-', ''), 1, 16)
+$$ LANGUAGE sql VOLATILE;
@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS users (
+    id                   text PRIMARY KEY DEFAULT generate_object_id(),
+    "userName"           varchar(100),
+    email                varchar(150) NOT NULL UNIQUE,
+    "noHandphone"        varchar(20),
+    password             text NOT NULL,
+    "activeUser"         boolean DEFAULT false,
+    role                 varchar(20) DEFAULT 'user',
+    "verificationToken"  text,
+    "apiKeyAI"           text,
+    "profilePicture"     text,
+    "userBilling"        bigint DEFAULT 0,
+    "createdAt"          timestamptz DEFAULT now()
+);
+
+-- Kolom yang ditambahkan setelah tabel users pertama kali dibuat
+-- (IF NOT EXISTS supaya aman dijalankan di database lama yang dibuat lewat AutoMigrate)
+ALTER TABLE users ADD COLUMN IF NOT EXISTS "verificationSentAt" timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS "twoFactorEnabled" boolean DEFAULT false;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS "twoFactorSecret" text;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS "twoFactorLastStep" bigint DEFAULT 0;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS "deactivatedAt" timestamptz;
+
+-- Lookup login by username (case-insensitive) dan nomor HP
+CREATE INDEX IF NOT EXISTS idx_users_user_name_lower ON users (LOWER("userName"));
+CREATE INDEX IF NOT EXISTS idx_users_no_handphone ON users ("noHandphone");
@@ -0,0 +1,30 @@
+CREATE TABLE IF NOT EXISTS sessions (
+    id             text PRIMARY KEY DEFAULT generate_object_id(),
+    "userId"       text NOT NULL,
+    jti            varchar(64) NOT NULL,
+    device         varchar(255),
+    "ipAddress"    varchar(64),
+    "createdAt"    timestamptz,
+    "lastSeenAt"   timestamptz,
+    "revokedAt"    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_sessions_user_id ON sessions ("userId");
+CREATE UNIQUE INDEX IF NOT EXISTS idx_sessions_jti ON sessions (jti);
+
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id               text PRIMARY KEY DEFAULT generate_object_id(),
+    "userId"         text NOT NULL,
+    "sessionId"      text NOT NULL,
+    "familyId"       varchar(64) NOT NULL,
+    "tokenHash"      varchar(64) NOT NULL,
+    "replacedById"   text,
+    "expiresAt"      timestamptz NOT NULL,
+    "revokedAt"      timestamptz,
+    "createdAt"      timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens ("userId");
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_session_id ON refresh_tokens ("sessionId");
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens ("familyId");
+CREATE UNIQUE INDEX IF NOT EXISTS idx_refresh_tokens_token_hash ON refresh_tokens ("tokenHash");
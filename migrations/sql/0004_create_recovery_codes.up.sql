@@ -0,0 +1,9 @@
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id            text PRIMARY KEY DEFAULT generate_object_id(),
+    "userId"      text NOT NULL,
+    "codeHash"    varchar(64) NOT NULL,
+    "usedAt"      timestamptz,
+    "createdAt"   timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes ("userId");
@@ -0,0 +1,7 @@
+-- key: "account:<userId>", "identifier:<identifier>" atau "ip:<address>"
+CREATE TABLE IF NOT EXISTS login_attempts (
+    key              varchar(191) PRIMARY KEY,
+    failures         bigint NOT NULL DEFAULT 0,
+    "lastFailedAt"   timestamptz,
+    "lockedUntil"    timestamptz
+);
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS audit_events (
+    id             text PRIMARY KEY DEFAULT generate_object_id(),
+    "actorId"      text,
+    action         varchar(100) NOT NULL,
+    "targetId"     text,
+    metadata       text,
+    "ipAddress"    varchar(64),
+    "userAgent"    varchar(255),
+    "requestId"    varchar(128),
+    "createdAt"    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_audit_events_actor_id ON audit_events ("actorId");
+CREATE INDEX IF NOT EXISTS idx_audit_events_action ON audit_events (action);
+CREATE INDEX IF NOT EXISTS idx_audit_events_target_id ON audit_events ("targetId");
+CREATE INDEX IF NOT EXISTS idx_audit_events_created_at ON audit_events ("createdAt");
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id             text PRIMARY KEY DEFAULT generate_object_id(),
+    "userId"       text NOT NULL,
+    purpose        varchar(32) NOT NULL,
+    "tokenHash"    varchar(64) NOT NULL,
+    "expiresAt"    timestamptz NOT NULL,
+    "consumedAt"   timestamptz,
+    "createdAt"    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_tokens_user_id ON user_tokens ("userId");
+CREATE UNIQUE INDEX IF NOT EXISTS idx_user_tokens_token_hash ON user_tokens ("tokenHash");
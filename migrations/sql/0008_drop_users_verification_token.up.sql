@@ -0,0 +1,2 @@
+-- Token verifikasi / reset password sekarang disimpan di user_tokens
+ALTER TABLE users DROP COLUMN IF EXISTS "verificationToken";
@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS email_outbox (
+    id               text PRIMARY KEY DEFAULT generate_object_id(),
+    recipient        varchar(150) NOT NULL,
+    subject          varchar(255) NOT NULL,
+    "htmlBody"       text NOT NULL,
+    status           varchar(16) NOT NULL DEFAULT 'pending',
+    attempts         integer DEFAULT 0,
+    "nextAttemptAt"  timestamptz NOT NULL,
+    "lastError"      text,
+    "sentAt"         timestamptz,
+    "createdAt"      timestamptz,
+    "updatedAt"      timestamptz
+);
+
+-- Worker hanya mencari email pending yang sudah waktunya dikirim
+CREATE INDEX IF NOT EXISTS idx_email_outbox_pending ON email_outbox ("nextAttemptAt") WHERE status = 'pending';
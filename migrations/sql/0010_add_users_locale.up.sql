@@ -0,0 +1,2 @@
+-- Bahasa email user (en / id), akun lama tetap memakai email bahasa Inggris
+ALTER TABLE users ADD COLUMN IF NOT EXISTS locale varchar(8) DEFAULT 'en';
@@ -0,0 +1,2 @@
+-- Alternatif plain text untuk email multipart
+ALTER TABLE email_outbox ADD COLUMN IF NOT EXISTS "textBody" text;
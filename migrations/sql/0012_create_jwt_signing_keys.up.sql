@@ -0,0 +1,11 @@
+CREATE TABLE IF NOT EXISTS jwt_signing_keys (
+    id             text PRIMARY KEY DEFAULT generate_object_id(),
+    algorithm      varchar(10) NOT NULL,
+    "privateKey"   text NOT NULL,
+    "publicKey"    text NOT NULL,
+    "activatesAt"  timestamptz NOT NULL,
+    "expiresAt"    timestamptz,
+    "createdAt"    timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_jwt_signing_keys_activates_at ON jwt_signing_keys ("activatesAt");
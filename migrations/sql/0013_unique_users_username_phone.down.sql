@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_users_user_name_lower_unique;
+DROP INDEX IF EXISTS idx_users_no_handphone_unique;
+
+CREATE INDEX IF NOT EXISTS idx_users_user_name_lower ON users (LOWER("userName"));
+CREATE INDEX IF NOT EXISTS idx_users_no_handphone ON users ("noHandphone");
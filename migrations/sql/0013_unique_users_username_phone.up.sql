@@ -0,0 +1,32 @@
+-- Username (case-insensitive) dan nomor HP harus unik supaya login lewat username / nomor HP tidak ambigu
+-- (cek di aplikasi saja tidak cukup untuk dua registrasi yang berjalan bersamaan)
+
+-- Nomor HP lama yang masih tersimpan dengan format 08xx / 628xx dinormalisasi ke +62xxxx
+-- (sama dengan utils.NormalizePhoneNumber) supaya nomor yang sama selalu bentrok di unique index
+UPDATE users
+SET "noHandphone" = '+62' || regexp_replace(regexp_replace("noHandphone", '[ ().-]', '', 'g'), '^(\+?62|0)', '')
+WHERE regexp_replace("noHandphone", '[ ().-]', '', 'g') ~ '^(\+?62|0)?8[0-9]{8,12}$';
+
+DO $$
+BEGIN
+    IF EXISTS (
+        SELECT 1 FROM users WHERE "userName" <> ''
+        GROUP BY LOWER("userName") HAVING COUNT(*) > 1
+    ) THEN
+        RAISE EXCEPTION 'users contains duplicate usernames (case-insensitive), resolve them before running this migration';
+    END IF;
+
+    IF EXISTS (
+        SELECT 1 FROM users WHERE "noHandphone" <> ''
+        GROUP BY "noHandphone" HAVING COUNT(*) > 1
+    ) THEN
+        RAISE EXCEPTION 'users contains duplicate phone numbers, resolve them before running this migration';
+    END IF;
+END $$;
+
+DROP INDEX IF EXISTS idx_users_user_name_lower;
+DROP INDEX IF EXISTS idx_users_no_handphone;
+
+-- Nama index dipakai repositories.GormUserRepository untuk memetakan unique violation ke error yang tepat
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_user_name_lower_unique ON users (LOWER("userName")) WHERE "userName" <> '';
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_no_handphone_unique ON users ("noHandphone") WHERE "noHandphone" <> '';
@@ -0,0 +1,2 @@
+-- Isi email yang sudah dihapus tidak bisa dikembalikan
+SELECT 1;
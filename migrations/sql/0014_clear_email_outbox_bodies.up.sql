@@ -0,0 +1,3 @@
+-- Isi email berisi link dengan token (verifikasi email, reset password, unlock akun),
+-- hapus isi email yang sudah terkirim / dead letter (sekarang dilakukan worker saat MarkSent / MarkFailed)
+UPDATE email_outbox SET "htmlBody" = '', "textBody" = NULL WHERE status IN ('sent', 'dead');
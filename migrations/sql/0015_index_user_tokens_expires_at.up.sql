@@ -0,0 +1,2 @@
+-- Token user_tokens yang sudah lama expired dihapus berkala (services.AuthService.PurgeUserTokens)
+CREATE INDEX IF NOT EXISTS idx_user_tokens_expires_at ON user_tokens ("expiresAt");
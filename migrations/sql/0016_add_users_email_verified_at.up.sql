@@ -0,0 +1,12 @@
+-- Verifikasi email dicatat terpisah dari activeUser, supaya admin yang mengaktifkan ulang akun
+-- tidak sekaligus memverifikasi email yang belum pernah diverifikasi
+ALTER TABLE users ADD COLUMN IF NOT EXISTS "emailVerifiedAt" timestamptz;
+
+-- Akun lama: aktif, pernah verifikasi email (audit), atau pernah login berarti email sudah diverifikasi
+UPDATE users SET "emailVerifiedAt" = COALESCE("createdAt", now())
+WHERE "emailVerifiedAt" IS NULL
+  AND (
+    "activeUser" = true
+    OR EXISTS (SELECT 1 FROM audit_events WHERE "targetId" = users.id AND action = 'auth.email.verified')
+    OR EXISTS (SELECT 1 FROM sessions WHERE "userId" = users.id)
+  );
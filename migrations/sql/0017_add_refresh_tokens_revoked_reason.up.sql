@@ -0,0 +1,9 @@
+-- Bedakan token yang di-revoke karena rotasi dari token yang di-revoke karena logout,
+-- supaya refresh token lama setelah logout tidak dianggap sebagai reuse
+ALTER TABLE refresh_tokens ADD COLUMN IF NOT EXISTS "revokedReason" varchar(32);
+
+UPDATE refresh_tokens SET "revokedReason" = CASE
+    WHEN "replacedById" IS NOT NULL AND "replacedById" <> '' THEN 'rotated'
+    ELSE 'session_revoked'
+  END
+WHERE "revokedAt" IS NOT NULL AND "revokedReason" IS NULL;
@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// AdminUserQuery - query parameter list user (GET /admin/users)
+type AdminUserQuery struct {
+	Page     int    `query:"page"`     // mulai dari 1
+	PageSize int    `query:"pageSize"` // default 20, maksimal 100
+	Search   string `query:"search"`   // cari di username, email, dan nomor HP
+	Role     string `query:"role"`     // filter role: user / admin
+	Active   string `query:"active"`   // filter status: true / false
+	Sort     string `query:"sort"`     // createdAt, userName, email; prefix "-" untuk descending (default -createdAt)
+}
+
+// AdminUser - data user untuk admin (tanpa password, secret 2FA, dan token)
+type AdminUser struct {
+	ID               string     `json:"id"`
+	UserName         string     `json:"username"`
+	Email            string     `json:"email"`
+	NoHandphone      string     `json:"noHandphone"`
+	Role             string     `json:"role"`
+	Locale           string     `json:"locale"`
+	ActiveUser       bool       `json:"activeUser"`
+	EmailVerifiedAt  *time.Time `json:"emailVerifiedAt,omitempty"`
+	DeactivatedAt    *time.Time `json:"deactivatedAt,omitempty"`
+	TwoFactorEnabled bool       `json:"twoFactorEnabled"`
+	UserBilling      int64      `json:"userBilling"`
+	CreatedAt        time.Time  `json:"createdAt"`
+}
+
+type AdminUserListResponse struct {
+	Users    []AdminUser `json:"users"`
+	Page     int         `json:"page"`
+	PageSize int         `json:"pageSize"`
+	Total    int64       `json:"total"`
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=user admin"`
+}
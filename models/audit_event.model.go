@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// AuditEvent - catatan aksi penting (siapa melakukan apa ke siapa), append-only
+// Metadata berisi JSON detail tambahan, contoh {"from":"user","to":"admin"} untuk perubahan role
+type AuditEvent struct {
+	ID        string    `gorm:"primaryKey;type:text;default:generate_object_id()" json:"id"`
+	ActorID   string    `gorm:"type:text;index;column:actorId" json:"actorId"` // kosong jika aksi dilakukan sistem / anonim
+	Action    string    `gorm:"type:varchar(100);not null;index;column:action" json:"action"`
+	TargetID  string    `gorm:"type:text;index;column:targetId" json:"targetId"`
+	Metadata  string    `gorm:"type:text;column:metadata" json:"metadata,omitempty"`
+	IPAddress string    `gorm:"type:varchar(64);column:ipAddress" json:"ipAddress"`
+	UserAgent string    `gorm:"type:varchar(255);column:userAgent" json:"userAgent"`
+	RequestID string    `gorm:"type:varchar(128);column:requestId" json:"requestId"`
+	CreatedAt time.Time `gorm:"index;column:createdAt" json:"createdAt"`
+}
+
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// AuditEventQuery - query parameter list audit event (GET /admin/audit-events)
+type AuditEventQuery struct {
+	Page     int    `query:"page"`     // mulai dari 1
+	PageSize int    `query:"pageSize"` // default 20, maksimal 100
+	ActorID  string `query:"actorId"`
+	TargetID string `query:"targetId"`
+	Action   string `query:"action"` // exact match, atau prefix jika diakhiri "*" (contoh auth.login.*)
+	From     string `query:"from"`   // RFC3339, inklusif
+	To       string `query:"to"`     // RFC3339, eksklusif
+}
+
+type AuditEventListResponse struct {
+	Events   []AuditEvent `json:"events"`
+	Page     int          `json:"page"`
+	PageSize int          `json:"pageSize"`
+	Total    int64        `json:"total"`
+}
@@ -1,21 +1,48 @@
 package models
 
+import "time"
+
+// Rule tag `validate` dijelaskan di utils/validator.go
+
 type RegisterRequest struct {
-	UserName        string `json:"userName"`
-	Email           string `json:"email"`
-	NoHandphone     string `json:"noHandphone"`
-	Password        string `json:"password"`
-	ConfirmPassword string `json:"confirmPassword"`
+	UserName        string `json:"userName" validate:"required,username"`
+	Email           string `json:"email" validate:"required,email,max=150"`
+	NoHandphone     string `json:"noHandphone" validate:"required,phone"`
+	Password        string `json:"password" validate:"required"`
+	ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=Password"`
+	Locale          string `json:"locale" validate:"oneof=en id"` // opsional, default dari header Accept-Language
 }
 
 type LoginRequest struct {
-	Identifier string `json:"identifier"`
-	Password   string `json:"password"`
+	Identifier string `json:"identifier" validate:"required,max=150"` // email, username, atau nomor HP
+	Password   string `json:"password" validate:"required"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required"`
+	ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=NewPassword"`
+}
+
+type ResetPasswordRequest struct {
+	Token           string `json:"token" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required"`
+	ConfirmPassword string `json:"confirmPassword" validate:"required,eqfield=NewPassword"`
+}
+
+type UpdateLocaleRequest struct {
+	Locale string `json:"locale" validate:"required,oneof=en id"`
+}
+
+type EmailRequest struct {
+	Email string `json:"email" validate:"required,email,max=150"`
 }
 
 type AuthResponse struct {
-	UserName string `json:"username"`
-	Message  string `json:"message"`
+	UserName    string `json:"username"`
+	Message     string `json:"message"`
+	MFARequired bool   `json:"mfaRequired,omitempty"` // true → kirim kode 2FA ke /auth/login/2fa
+	MFAToken    string `json:"mfaToken,omitempty"`
 }
 
 type UserInfo struct {
@@ -24,10 +51,56 @@ type UserInfo struct {
 	Role     string `json:"role"`
 }
 
+type VerifyEmailResponse struct {
+	Message         string `json:"message"`
+	AlreadyVerified bool   `json:"alreadyVerified"` // true jika akun sudah aktif sebelum link ini dibuka
+}
+
 type MessageResponse struct {
 	Message string `json:"message"`
 }
 
 type ErrorResponse struct {
-	Message string `json:"message"`
+	Code    string              `json:"code" example:"invalid_credentials"` // kode machine-readable yang stabil
+	Message string              `json:"message"`
+	Details map[string][]string `json:"details,omitempty"` // error per field (jika ada)
+}
+
+type ValidationErrorResponse struct {
+	Code    string              `json:"code" example:"validation_failed"`
+	Message string              `json:"message"`
+	Details map[string][]string `json:"details"` // key = nama field JSON, value = daftar pesan error
+}
+
+type SessionInfo struct {
+	ID         string    `json:"id"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ipAddress"`
+	CreatedAt  time.Time `json:"createdAt"`
+	LastSeenAt time.Time `json:"lastSeenAt"`
+	Current    bool      `json:"current"`
+}
+
+type TwoFactorSetupResponse struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauthUri"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,numeric,len=6"`
+}
+
+type TwoFactorDisableRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=20"` // kode TOTP atau recovery code
+}
+
+type TwoFactorLoginRequest struct {
+	MFAToken string `json:"mfaToken" validate:"required"`
+	Code     string `json:"code" validate:"required,max=20"` // kode TOTP atau recovery code
+}
+
+type RecoveryCodesResponse struct {
+	Message       string   `json:"message"`
+	RecoveryCodes []string `json:"recoveryCodes"`
 }
@@ -0,0 +1,13 @@
+package models
+
+// ConfigSetting - satu nilai konfigurasi di dump admin, nilai secret sudah disensor ("********")
+type ConfigSetting struct {
+	Key    string `json:"key" example:"DB_HOST"`
+	Value  string `json:"value" example:"localhost"`
+	Source string `json:"source" example:"env"` // default / file / env / generated
+}
+
+type AdminConfigResponse struct {
+	Environment string          `json:"environment" example:"production"`
+	Settings    []ConfigSetting `json:"settings"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// Status EmailOutbox
+const (
+	EmailStatusPending = "pending" // menunggu dikirim / dicoba ulang
+	EmailStatusSent    = "sent"
+	EmailStatusDead    = "dead" // gagal sampai batas percobaan, tidak dicoba lagi (dead letter)
+)
+
+// EmailOutbox - email yang menunggu dikirim oleh worker (transactional outbox)
+// Disimpan dalam transaksi yang sama dengan perubahan data yang memicunya, lalu dikirim di background
+// HTMLBody / TextBody dikosongkan setelah terkirim atau jadi dead letter (berisi link dengan token)
+type EmailOutbox struct {
+	ID            string     `gorm:"primaryKey;type:text;default:generate_object_id()"`
+	Recipient     string     `gorm:"type:varchar(150);not null;column:recipient"`
+	Subject       string     `gorm:"type:varchar(255);not null;column:subject"`
+	HTMLBody      string     `gorm:"type:text;not null;column:htmlBody"`
+	TextBody      string     `gorm:"type:text;column:textBody"` // alternatif plain text
+	Status        string     `gorm:"type:varchar(16);not null;default:pending;column:status"`
+	Attempts      int        `gorm:"default:0;column:attempts"`
+	NextAttemptAt time.Time  `gorm:"not null;column:nextAttemptAt"` // email baru diambil worker setelah waktu ini
+	LastError     string     `gorm:"type:text;column:lastError"`
+	SentAt        *time.Time `gorm:"column:sentAt"`
+	CreatedAt     time.Time  `gorm:"column:createdAt"`
+	UpdatedAt     time.Time  `gorm:"column:updatedAt"`
+}
+
+func (EmailOutbox) TableName() string {
+	return "email_outbox"
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// LoginAttempt - counter gagal login per key ("account:<id>" atau "ip:<address>")
+type LoginAttempt struct {
+	Key          string     `gorm:"primaryKey;type:varchar(191);column:key"`
+	Failures     int        `gorm:"not null;default:0;column:failures"`
+	LastFailedAt time.Time  `gorm:"column:lastFailedAt"`
+	LockedUntil  *time.Time `gorm:"column:lockedUntil"`
+}
+
+func (LoginAttempt) TableName() string {
+	return "login_attempts"
+}
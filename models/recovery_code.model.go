@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// RecoveryCode - kode cadangan 2FA sekali pakai (disimpan dalam bentuk hash)
+type RecoveryCode struct {
+	ID        string     `gorm:"primaryKey;type:text;default:generate_object_id()" json:"id"`
+	UserID    string     `gorm:"type:text;not null;index;column:userId"`
+	CodeHash  string     `gorm:"type:varchar(64);not null;column:codeHash"`
+	UsedAt    *time.Time `gorm:"column:usedAt"`
+	CreatedAt time.Time  `gorm:"column:createdAt"`
+}
+
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
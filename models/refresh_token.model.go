@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+// Alasan refresh token di-revoke (kolom revokedReason)
+// Hanya token yang sudah dirotasi (atau family-nya dimatikan karena replay) yang dianggap reuse jika dipakai lagi
+const (
+	RefreshTokenRevokedRotated = "rotated"         // ditukar dengan token baru lewat refresh
+	RefreshTokenRevokedReuse   = "reuse_detected"  // family dimatikan karena token lama dipakai ulang
+	RefreshTokenRevokedSession = "session_revoked" // session-nya di-revoke (logout, reset password, admin)
+)
+
+// RefreshToken - refresh token opaque yang disimpan di database (hanya hash-nya)
+// Semua token hasil rotasi dari satu login berbagi FamilyID yang sama
+type RefreshToken struct {
+	ID            string     `gorm:"primaryKey;type:text;default:generate_object_id()" json:"id"`
+	UserID        string     `gorm:"type:text;not null;index;column:userId"`
+	SessionID     string     `gorm:"type:text;not null;index;column:sessionId"`
+	FamilyID      string     `gorm:"type:varchar(64);not null;index;column:familyId"`
+	TokenHash     string     `gorm:"type:varchar(64);not null;uniqueIndex;column:tokenHash"`
+	ReplacedByID  string     `gorm:"type:text;column:replacedById"`
+	ExpiresAt     time.Time  `gorm:"not null;column:expiresAt"`
+	RevokedAt     *time.Time `gorm:"column:revokedAt"`
+	RevokedReason string     `gorm:"type:varchar(32);column:revokedReason"`
+	CreatedAt     time.Time  `gorm:"column:createdAt"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
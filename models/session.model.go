@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Session - satu record per login (per device)
+// JTI selalu berisi jti dari auth_token terakhir yang diterbitkan untuk session ini
+type Session struct {
+	ID         string     `gorm:"primaryKey;type:text;default:generate_object_id()" json:"id"`
+	UserID     string     `gorm:"type:text;not null;index;column:userId" json:"-"`
+	JTI        string     `gorm:"type:varchar(64);not null;uniqueIndex;column:jti" json:"-"`
+	Device     string     `gorm:"type:varchar(255);column:device" json:"device"`
+	IPAddress  string     `gorm:"type:varchar(64);column:ipAddress" json:"ipAddress"`
+	CreatedAt  time.Time  `gorm:"column:createdAt" json:"createdAt"`
+	LastSeenAt time.Time  `gorm:"column:lastSeenAt" json:"lastSeenAt"`
+	RevokedAt  *time.Time `gorm:"column:revokedAt" json:"revokedAt,omitempty"`
+}
+
+func (Session) TableName() string {
+	return "sessions"
+}
@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// SigningKey - key pair untuk sign JWT (RS256 / EdDSA), ID dipakai sebagai "kid" di header token
+// Key dengan ActivatesAt paling baru (dan sudah lewat) dipakai untuk sign,
+// semua key yang belum ExpiresAt dipakai untuk verify dan dipublikasikan di JWKS
+type SigningKey struct {
+	ID          string     `gorm:"primaryKey;type:text;default:generate_object_id()"`
+	Algorithm   string     `gorm:"type:varchar(10);not null;column:algorithm"`
+	PrivateKey  string     `gorm:"type:text;not null;column:privateKey"` // PKCS#8, dienkripsi AES-GCM (lihat services.KeyManager)
+	PublicKey   string     `gorm:"type:text;not null;column:publicKey"`  // PKIX PEM
+	ActivatesAt time.Time  `gorm:"not null;column:activatesAt"`          // mulai dipakai sign (dipublikasikan lebih dulu di JWKS)
+	ExpiresAt   *time.Time `gorm:"column:expiresAt"`                     // diisi saat key diganti: key baru aktif + grace period
+	CreatedAt   time.Time  `gorm:"column:createdAt"`
+}
+
+func (SigningKey) TableName() string {
+	return "jwt_signing_keys"
+}
+
+// JWK - public key dalam format JSON Web Key (RFC 7517)
+type JWK struct {
+	Kty string `json:"kty" example:"OKP"`
+	Kid string `json:"kid" example:"65f1c0e2a9b8d7c6e5f4a3b2"`
+	Use string `json:"use" example:"sig"`
+	Alg string `json:"alg" example:"EdDSA"`
+	Crv string `json:"crv,omitempty" example:"Ed25519"` // OKP
+	X   string `json:"x,omitempty"`                     // OKP
+	N   string `json:"n,omitempty"`                     // RSA
+	E   string `json:"e,omitempty"`                     // RSA
+}
+
+// JWKSResponse - isi /.well-known/jwks.json
+type JWKSResponse struct {
+	Keys []JWK `json:"keys"`
+}
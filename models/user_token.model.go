@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Purpose UserToken
+const (
+	TokenPurposeEmailVerification = "email_verification"
+	TokenPurposePasswordReset     = "password_reset"
+	TokenPurposeMFAPending        = "mfa_pending" // jti mfaToken login 2FA (lihat utils.GenerateMFAToken)
+	TokenPurposeAccountUnlock     = "account_unlock"
+)
+
+// UserToken - token sekali pakai (verifikasi email, reset password, unlock akun, jti mfaToken login 2FA)
+// Hanya hash SHA-256 yang disimpan, token asli hanya ada di link email / mfaToken
+type UserToken struct {
+	ID         string     `gorm:"primaryKey;type:text;default:generate_object_id()"`
+	UserID     string     `gorm:"type:text;not null;index;column:userId"`
+	Purpose    string     `gorm:"type:varchar(32);not null;column:purpose"`
+	TokenHash  string     `gorm:"type:varchar(64);not null;uniqueIndex;column:tokenHash"`
+	ExpiresAt  time.Time  `gorm:"not null;column:expiresAt"`
+	ConsumedAt *time.Time `gorm:"column:consumedAt"` // diisi saat token dipakai atau dibatalkan
+	CreatedAt  time.Time  `gorm:"column:createdAt"`
+}
+
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
@@ -3,20 +3,26 @@ package models
 import "time"
 
 type Users struct {
-	ID                string    `gorm:"primaryKey;type:text;default:generate_object_id()" json:"id"`
-	UserName          string    `gorm:"type:varchar(100);column:userName"`
-	Email             string    `gorm:"type:varchar(150);unique;not null;column:email"`
-	NoHandphone       string    `gorm:"type:varchar(20);column:noHandphone"`
-	Password          string    `gorm:"type:text;not null;column:password"`
-	ActiveUser        bool      `gorm:"default:false;column:activeUser"`
-	Role              string    `gorm:"type:varchar(20);default:user;column:role"`
-	VerificationToken string    `gorm:"type:text;column:verificationToken"`
-	ApiKeyAI          string    `gorm:"type:text;column:apiKeyAI"`
-	ProfilePicture    string    `gorm:"type:text;column:profilePicture"`
-	UserBilling       int64     `gorm:"default:0;column:userBilling"`
-	CreatedAt         time.Time `gorm:"column:createdAt"`
+	ID                 string     `gorm:"primaryKey;type:text;default:generate_object_id()" json:"id"`
+	UserName           string     `gorm:"type:varchar(100);column:userName"`
+	Email              string     `gorm:"type:varchar(150);unique;not null;column:email"`
+	NoHandphone        string     `gorm:"type:varchar(20);column:noHandphone"`
+	Password           string     `gorm:"type:text;not null;column:password"`
+	ActiveUser         bool       `gorm:"default:false;column:activeUser"`
+	DeactivatedAt      *time.Time `gorm:"column:deactivatedAt"`   // diisi jika akun dinonaktifkan admin (bukan sekadar belum verifikasi)
+	EmailVerifiedAt    *time.Time `gorm:"column:emailVerifiedAt"` // diisi saat email diverifikasi (link email atau admin)
+	Role               string     `gorm:"type:varchar(20);default:user;column:role"`
+	Locale             string     `gorm:"type:varchar(8);default:en;column:locale"` // bahasa email (en / id)
+	VerificationSentAt *time.Time `gorm:"column:verificationSentAt"`                // waktu email verifikasi terakhir dikirim (cooldown resend)
+	ApiKeyAI           string     `gorm:"type:text;column:apiKeyAI"`
+	ProfilePicture     string     `gorm:"type:text;column:profilePicture"`
+	UserBilling        int64      `gorm:"default:0;column:userBilling"`
+	TwoFactorEnabled   bool       `gorm:"default:false;column:twoFactorEnabled"`
+	TwoFactorSecret    string     `gorm:"type:text;column:twoFactorSecret"`   // terenkripsi (lihat services.AuthService.sealTOTPSecret)
+	TwoFactorLastStep  int64      `gorm:"default:0;column:twoFactorLastStep"` // time-step TOTP terakhir yang dipakai (anti replay)
+	CreatedAt          time.Time  `gorm:"column:createdAt"`
 }
 
 func (Users) TableName() string {
 	return "users"
-}
\ No newline at end of file
+}
@@ -0,0 +1,136 @@
+package repositories
+
+import (
+	"belajar-go-fiber/models"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditRepository - penyimpanan audit event (append-only)
+// Implementasi: GormAuditRepository (production) dan MemoryAuditRepository (unit test tanpa database)
+type AuditRepository interface {
+	// Create - simpan satu audit event
+	Create(event *models.AuditEvent) error
+	// List - list audit event terbaru dulu, dengan filter actor / target / action / rentang waktu
+	// query.Page dan query.PageSize harus sudah dinormalisasi (>= 1) oleh service
+	List(query models.AuditEventQuery, from, to *time.Time) ([]models.AuditEvent, int64, error)
+}
+
+// ==================== GORM REPOSITORY ====================
+
+// GormAuditRepository - AuditRepository di tabel audit_events lewat GORM
+type GormAuditRepository struct {
+	db *gorm.DB
+}
+
+func NewGormAuditRepository(db *gorm.DB) *GormAuditRepository {
+	return &GormAuditRepository{db: db}
+}
+
+func (r *GormAuditRepository) Create(event *models.AuditEvent) error {
+	return r.db.Create(event).Error
+}
+
+func (r *GormAuditRepository) List(query models.AuditEventQuery, from, to *time.Time) ([]models.AuditEvent, int64, error) {
+	db := r.db.Model(&models.AuditEvent{})
+
+	if query.ActorID != "" {
+		db = db.Where("\"actorId\" = ?", query.ActorID)
+	}
+	if query.TargetID != "" {
+		db = db.Where("\"targetId\" = ?", query.TargetID)
+	}
+	if prefix, ok := strings.CutSuffix(query.Action, "*"); ok {
+		db = db.Where("action LIKE ?", escapeLike(prefix)+"%")
+	} else if query.Action != "" {
+		db = db.Where("action = ?", query.Action)
+	}
+	if from != nil {
+		db = db.Where("\"createdAt\" >= ?", *from)
+	}
+	if to != nil {
+		db = db.Where("\"createdAt\" < ?", *to)
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var events []models.AuditEvent
+	err := db.Order("\"createdAt\" DESC").
+		Order("id").
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&events).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return events, total, nil
+}
+
+// ==================== MEMORY REPOSITORY ====================
+
+// MemoryAuditRepository - AuditRepository di memory, untuk unit test service tanpa database
+type MemoryAuditRepository struct {
+	mu     sync.Mutex
+	events []models.AuditEvent
+}
+
+func NewMemoryAuditRepository() *MemoryAuditRepository {
+	return &MemoryAuditRepository{}
+}
+
+func (r *MemoryAuditRepository) Create(event *models.AuditEvent) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if event.ID == "" {
+		event.ID = newMemoryID()
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	r.events = append(r.events, *event)
+	return nil
+}
+
+func (r *MemoryAuditRepository) List(query models.AuditEventQuery, from, to *time.Time) ([]models.AuditEvent, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prefix, isPrefix := strings.CutSuffix(query.Action, "*")
+	var events []models.AuditEvent
+	for _, event := range r.events {
+		if query.ActorID != "" && event.ActorID != query.ActorID {
+			continue
+		}
+		if query.TargetID != "" && event.TargetID != query.TargetID {
+			continue
+		}
+		if isPrefix && !strings.HasPrefix(event.Action, prefix) {
+			continue
+		}
+		if !isPrefix && query.Action != "" && event.Action != query.Action {
+			continue
+		}
+		if from != nil && event.CreatedAt.Before(*from) {
+			continue
+		}
+		if to != nil && !event.CreatedAt.Before(*to) {
+			continue
+		}
+		events = append(events, event)
+	}
+
+	// Terbaru dulu, event dengan waktu sama tetap urut sesuai urutan dicatat
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].CreatedAt.After(events[j].CreatedAt)
+	})
+
+	return paginate(events, query.Page, query.PageSize), int64(len(events)), nil
+}
@@ -0,0 +1,212 @@
+package repositories
+
+import (
+	"belajar-go-fiber/models"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// EmailOutboxRepository - antrian email yang dikirim worker di background
+// Implementasi: GormEmailOutboxRepository (production) dan MemoryEmailOutboxRepository (unit test tanpa database)
+type EmailOutboxRepository interface {
+	// Enqueue - simpan email baru dengan status pending
+	Enqueue(email *models.EmailOutbox) error
+	// ClaimDue - ambil maksimal limit email pending yang nextAttemptAt <= now, attempts ditambah 1 dan
+	// nextAttemptAt digeser ke now+lease supaya tidak diambil worker lain selama sedang dikirim
+	ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.EmailOutbox, error)
+	// MarkSent - tandai email sudah terkirim dan hapus isinya (link email berisi token)
+	MarkSent(id string, sentAt time.Time) error
+	// MarkFailed - catat error pengiriman; dicoba lagi pada retryAt, atau jadi dead letter jika retryAt nil
+	// (isi email dead letter ikut dihapus seperti MarkSent)
+	MarkFailed(id, lastError string, retryAt *time.Time) error
+}
+
+// ==================== GORM REPOSITORY ====================
+
+// GormEmailOutboxRepository - EmailOutboxRepository di tabel email_outbox lewat GORM
+type GormEmailOutboxRepository struct {
+	db *gorm.DB
+}
+
+func NewGormEmailOutboxRepository(db *gorm.DB) *GormEmailOutboxRepository {
+	return &GormEmailOutboxRepository{db: db}
+}
+
+func (r *GormEmailOutboxRepository) Enqueue(email *models.EmailOutbox) error {
+	return r.db.Create(email).Error
+}
+
+func (r *GormEmailOutboxRepository) ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.EmailOutbox, error) {
+	var emails []models.EmailOutbox
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// SKIP LOCKED: baris yang sedang di-claim instance lain dilewati, bukan ditunggu
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE", Options: "SKIP LOCKED"}).
+			Where("status = ? AND \"nextAttemptAt\" <= ?", models.EmailStatusPending, now).
+			Order("\"nextAttemptAt\"").
+			Limit(limit).
+			Find(&emails).Error
+		if err != nil || len(emails) == 0 {
+			return err
+		}
+
+		ids := make([]string, len(emails))
+		for i := range emails {
+			ids[i] = emails[i].ID
+			emails[i].Attempts++
+			emails[i].NextAttemptAt = now.Add(lease)
+		}
+
+		return tx.Model(&models.EmailOutbox{}).
+			Where("id IN ?", ids).
+			Updates(map[string]interface{}{
+				"attempts":      gorm.Expr("attempts + 1"),
+				"nextAttemptAt": now.Add(lease),
+				"updatedAt":     now,
+			}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return emails, nil
+}
+
+func (r *GormEmailOutboxRepository) MarkSent(id string, sentAt time.Time) error {
+	return r.db.Model(&models.EmailOutbox{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"status":    models.EmailStatusSent,
+			"sentAt":    sentAt,
+			"lastError": "",
+			"htmlBody":  "",
+			"textBody":  "",
+			"updatedAt": sentAt,
+		}).Error
+}
+
+func (r *GormEmailOutboxRepository) MarkFailed(id, lastError string, retryAt *time.Time) error {
+	updates := map[string]interface{}{
+		"lastError": lastError,
+		"updatedAt": time.Now(),
+	}
+	if retryAt != nil {
+		updates["nextAttemptAt"] = *retryAt
+	} else {
+		updates["status"] = models.EmailStatusDead
+		updates["htmlBody"] = ""
+		updates["textBody"] = ""
+	}
+
+	return r.db.Model(&models.EmailOutbox{}).
+		Where("id = ?", id).
+		Updates(updates).Error
+}
+
+// ==================== MEMORY REPOSITORY ====================
+
+// MemoryEmailOutboxRepository - EmailOutboxRepository di memory, untuk unit test service tanpa database
+type MemoryEmailOutboxRepository struct {
+	mu     sync.Mutex
+	emails map[string]models.EmailOutbox
+}
+
+func NewMemoryEmailOutboxRepository() *MemoryEmailOutboxRepository {
+	return &MemoryEmailOutboxRepository{emails: make(map[string]models.EmailOutbox)}
+}
+
+func (r *MemoryEmailOutboxRepository) Enqueue(email *models.EmailOutbox) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if email.ID == "" {
+		email.ID = newMemoryID()
+	}
+	if email.Status == "" {
+		email.Status = models.EmailStatusPending
+	}
+	now := time.Now()
+	if email.CreatedAt.IsZero() {
+		email.CreatedAt = now
+	}
+	email.UpdatedAt = now
+	r.emails[email.ID] = *email
+	return nil
+}
+
+func (r *MemoryEmailOutboxRepository) ClaimDue(now time.Time, limit int, lease time.Duration) ([]models.EmailOutbox, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var due []models.EmailOutbox
+	for _, email := range r.emails {
+		if email.Status == models.EmailStatusPending && !email.NextAttemptAt.After(now) {
+			due = append(due, email)
+		}
+	}
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	for i := range due {
+		due[i].Attempts++
+		due[i].NextAttemptAt = now.Add(lease)
+		due[i].UpdatedAt = now
+		r.emails[due[i].ID] = due[i]
+	}
+	return due, nil
+}
+
+func (r *MemoryEmailOutboxRepository) MarkSent(id string, sentAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if email, ok := r.emails[id]; ok {
+		email.Status = models.EmailStatusSent
+		email.SentAt = &sentAt
+		email.LastError = ""
+		email.HTMLBody, email.TextBody = "", ""
+		email.UpdatedAt = sentAt
+		r.emails[id] = email
+	}
+	return nil
+}
+
+func (r *MemoryEmailOutboxRepository) MarkFailed(id, lastError string, retryAt *time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if email, ok := r.emails[id]; ok {
+		email.LastError = lastError
+		email.UpdatedAt = time.Now()
+		if retryAt != nil {
+			email.NextAttemptAt = *retryAt
+		} else {
+			email.Status = models.EmailStatusDead
+			email.HTMLBody, email.TextBody = "", ""
+		}
+		r.emails[id] = email
+	}
+	return nil
+}
+
+// All - semua email di outbox (urut waktu dibuat), untuk assertion di unit test
+func (r *MemoryEmailOutboxRepository) All() []models.EmailOutbox {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	emails := make([]models.EmailOutbox, 0, len(r.emails))
+	for _, email := range r.emails {
+		emails = append(emails, email)
+	}
+	sort.SliceStable(emails, func(i, j int) bool {
+		return emails[i].CreatedAt.Before(emails[j].CreatedAt)
+	})
+	return emails
+}
@@ -0,0 +1,146 @@
+package repositories
+
+import (
+	"belajar-go-fiber/models"
+	"errors"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// LoginAttemptStore - penyimpanan counter gagal login
+// Implementasi: PostgresLoginAttemptStore (production) dan MemoryLoginAttemptStore (test / single instance)
+type LoginAttemptStore interface {
+	// Get - ambil counter untuk key, return nil jika belum ada
+	Get(key string) (*models.LoginAttempt, error)
+	// RecordFailure - tambah counter gagal; counter mulai dari 1 lagi jika gagal terakhir lebih lama dari window
+	RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginAttempt, error)
+	// Lock - kunci key sampai waktu tertentu
+	Lock(key string, until time.Time) error
+	// Reset - hapus counter dan lock (login sukses / unlock)
+	Reset(key string) error
+}
+
+// NewLoginAttemptStore - pilih implementasi store berdasarkan nama ("memory" atau "postgres")
+func NewLoginAttemptStore(kind string, db *gorm.DB) LoginAttemptStore {
+	if kind == "memory" {
+		return NewMemoryLoginAttemptStore()
+	}
+	return NewPostgresLoginAttemptStore(db)
+}
+
+// ==================== POSTGRES STORE ====================
+
+// PostgresLoginAttemptStore - simpan counter di tabel login_attempts (aman untuk banyak instance)
+type PostgresLoginAttemptStore struct {
+	db *gorm.DB
+}
+
+func NewPostgresLoginAttemptStore(db *gorm.DB) *PostgresLoginAttemptStore {
+	return &PostgresLoginAttemptStore{db: db}
+}
+
+func (s *PostgresLoginAttemptStore) Get(key string) (*models.LoginAttempt, error) {
+	var attempt models.LoginAttempt
+	err := s.db.Where("key = ?", key).First(&attempt).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &attempt, nil
+}
+
+func (s *PostgresLoginAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	attempt := models.LoginAttempt{Key: key, Failures: 1, LastFailedAt: now}
+
+	// Upsert atomic supaya request paralel tidak saling menimpa counter
+	err := s.db.Clauses(
+		clause.OnConflict{
+			Columns: []clause.Column{{Name: "key"}},
+			DoUpdates: clause.Assignments(map[string]interface{}{
+				"failures": gorm.Expr(
+					"CASE WHEN login_attempts.\"lastFailedAt\" < ? THEN 1 ELSE login_attempts.failures + 1 END",
+					now.Add(-window),
+				),
+				"lastFailedAt": now,
+			}),
+		},
+		clause.Returning{},
+	).Create(&attempt).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return &attempt, nil
+}
+
+func (s *PostgresLoginAttemptStore) Lock(key string, until time.Time) error {
+	return s.db.Model(&models.LoginAttempt{}).
+		Where("key = ?", key).
+		Update("lockedUntil", until).Error
+}
+
+func (s *PostgresLoginAttemptStore) Reset(key string) error {
+	return s.db.Where("key = ?", key).Delete(&models.LoginAttempt{}).Error
+}
+
+// ==================== MEMORY STORE ====================
+
+// MemoryLoginAttemptStore - simpan counter di memory (hilang saat restart, tidak shared antar instance)
+type MemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]models.LoginAttempt
+}
+
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{attempts: make(map[string]models.LoginAttempt)}
+}
+
+func (s *MemoryLoginAttemptStore) Get(key string) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok {
+		return nil, nil
+	}
+	return &attempt, nil
+}
+
+func (s *MemoryLoginAttemptStore) RecordFailure(key string, now time.Time, window time.Duration) (*models.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt, ok := s.attempts[key]
+	if !ok || attempt.LastFailedAt.Before(now.Add(-window)) {
+		attempt = models.LoginAttempt{Key: key, LockedUntil: attempt.LockedUntil}
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = now
+	s.attempts[key] = attempt
+
+	return &attempt, nil
+}
+
+func (s *MemoryLoginAttemptStore) Lock(key string, until time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt := s.attempts[key]
+	attempt.Key = key
+	attempt.LockedUntil = &until
+	s.attempts[key] = attempt
+	return nil
+}
+
+func (s *MemoryLoginAttemptStore) Reset(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
@@ -0,0 +1,169 @@
+package repositories
+
+import (
+	"belajar-go-fiber/models"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RefreshTokenRepository - refresh token opaque (hanya hash yang disimpan), dirotasi setiap dipakai
+// Implementasi: GormRefreshTokenRepository (production) dan MemoryRefreshTokenRepository (unit test tanpa database)
+type RefreshTokenRepository interface {
+	// Create - simpan refresh token baru
+	Create(token *models.RefreshToken) error
+	// FindByHash - gorm.ErrRecordNotFound jika token tidak ada
+	FindByHash(tokenHash string) (*models.RefreshToken, error)
+	// Rotate - revoke token lama dan simpan token pengganti dalam satu transaksi
+	// Return gorm.ErrRecordNotFound jika token lama sudah di-revoke (dipakai request lain)
+	Rotate(oldID string, newToken *models.RefreshToken) error
+	// RevokeFamily - revoke semua refresh token dalam satu family karena reuse terdeteksi
+	RevokeFamily(familyID string) error
+}
+
+// ==================== GORM REPOSITORY ====================
+
+// GormRefreshTokenRepository - RefreshTokenRepository di tabel refresh_tokens lewat GORM
+type GormRefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+func NewGormRefreshTokenRepository(db *gorm.DB) *GormRefreshTokenRepository {
+	return &GormRefreshTokenRepository{db: db}
+}
+
+func (r *GormRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+func (r *GormRefreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	err := r.db.Where("\"tokenHash\" = ?", tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+func (r *GormRefreshTokenRepository) Rotate(oldID string, newToken *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(newToken).Error; err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND \"revokedAt\" IS NULL", oldID).
+			Updates(map[string]interface{}{
+				"revokedAt":     time.Now(),
+				"revokedReason": models.RefreshTokenRevokedRotated,
+				"replacedById":  newToken.ID,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+func (r *GormRefreshTokenRepository) RevokeFamily(familyID string) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("\"familyId\" = ? AND \"revokedAt\" IS NULL", familyID).
+		Updates(map[string]interface{}{
+			"revokedAt":     time.Now(),
+			"revokedReason": models.RefreshTokenRevokedReuse,
+		}).Error
+}
+
+// ==================== MEMORY REPOSITORY ====================
+
+// MemoryRefreshTokenRepository - RefreshTokenRepository di memory, untuk unit test tanpa database
+type MemoryRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]models.RefreshToken
+}
+
+func NewMemoryRefreshTokenRepository() *MemoryRefreshTokenRepository {
+	return &MemoryRefreshTokenRepository{tokens: make(map[string]models.RefreshToken)}
+}
+
+func (r *MemoryRefreshTokenRepository) Create(token *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.create(token)
+}
+
+func (r *MemoryRefreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryRefreshTokenRepository) Rotate(oldID string, newToken *models.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	old, ok := r.tokens[oldID]
+	if !ok || old.RevokedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	if err := r.create(newToken); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	old.RevokedAt = &now
+	old.RevokedReason = models.RefreshTokenRevokedRotated
+	old.ReplacedByID = newToken.ID
+	r.tokens[oldID] = old
+	return nil
+}
+
+func (r *MemoryRefreshTokenRepository) RevokeFamily(familyID string) error {
+	r.revokeWhere(func(token models.RefreshToken) bool {
+		return token.FamilyID == familyID
+	}, time.Now(), models.RefreshTokenRevokedReuse)
+	return nil
+}
+
+// create - simpan token baru, r.mu harus sudah di-lock
+func (r *MemoryRefreshTokenRepository) create(token *models.RefreshToken) error {
+	for _, existing := range r.tokens {
+		if existing.TokenHash == token.TokenHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	if token.ID == "" {
+		token.ID = newMemoryID()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+// revokeWhere - revoke token yang belum di-revoke dan cocok dengan filter (juga dipakai MemorySessionRepository)
+func (r *MemoryRefreshTokenRepository) revokeWhere(match func(models.RefreshToken) bool, at time.Time, reason string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.RevokedAt == nil && match(token) {
+			token.RevokedAt = &at
+			token.RevokedReason = reason
+			r.tokens[id] = token
+		}
+	}
+}
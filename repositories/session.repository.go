@@ -0,0 +1,244 @@
+package repositories
+
+import (
+	"belajar-go-fiber/models"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SessionRepository - session login per device (tabel sessions)
+// Revoke dan RevokeUserSessions juga me-revoke refresh token milik session tersebut
+// Implementasi: GormSessionRepository (production) dan MemorySessionRepository (unit test tanpa database)
+type SessionRepository interface {
+	// Create - simpan session baru saat login
+	Create(session *models.Session) error
+	// FindByID - gorm.ErrRecordNotFound jika session tidak ada
+	FindByID(id string) (*models.Session, error)
+	// UpdateJTI - ganti jti session setelah auth_token baru diterbitkan (refresh)
+	UpdateJTI(id, jti string) error
+	// Touch - update lastSeenAt session
+	Touch(id string) error
+	// Revoke - revoke session beserta refresh token miliknya
+	Revoke(id string) error
+	// RevokeUserSessions - revoke semua session user (kecuali exceptID jika diisi) beserta refresh token-nya
+	RevokeUserSessions(userID, exceptID string) error
+	// FindActiveByUserID - list session user yang belum di-revoke (terbaru dulu)
+	FindActiveByUserID(userID string) ([]models.Session, error)
+}
+
+// ==================== GORM REPOSITORY ====================
+
+// GormSessionRepository - SessionRepository di tabel sessions dan refresh_tokens lewat GORM
+type GormSessionRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSessionRepository(db *gorm.DB) *GormSessionRepository {
+	return &GormSessionRepository{db: db}
+}
+
+func (r *GormSessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+func (r *GormSessionRepository) FindByID(id string) (*models.Session, error) {
+	var session models.Session
+	err := r.db.Where("id = ?", id).First(&session).Error
+	if err != nil {
+		return nil, err
+	}
+	return &session, nil
+}
+
+func (r *GormSessionRepository) UpdateJTI(id, jti string) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ? AND \"revokedAt\" IS NULL", id).
+		Updates(map[string]interface{}{
+			"jti":        jti,
+			"lastSeenAt": time.Now(),
+		}).Error
+}
+
+func (r *GormSessionRepository) Touch(id string) error {
+	return r.db.Model(&models.Session{}).
+		Where("id = ?", id).
+		Update("lastSeenAt", time.Now()).Error
+}
+
+func (r *GormSessionRepository) Revoke(id string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		if err := tx.Model(&models.Session{}).
+			Where("id = ? AND \"revokedAt\" IS NULL", id).
+			Update("revokedAt", now).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.RefreshToken{}).
+			Where("\"sessionId\" = ? AND \"revokedAt\" IS NULL", id).
+			Updates(revokedBySession(now)).Error
+	})
+}
+
+func (r *GormSessionRepository) RevokeUserSessions(userID, exceptID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		sessions := tx.Model(&models.Session{}).
+			Where("\"userId\" = ? AND \"revokedAt\" IS NULL", userID)
+		tokens := tx.Model(&models.RefreshToken{}).
+			Where("\"userId\" = ? AND \"revokedAt\" IS NULL", userID)
+		if exceptID != "" {
+			sessions = sessions.Where("id <> ?", exceptID)
+			tokens = tokens.Where("\"sessionId\" <> ?", exceptID)
+		}
+
+		if err := sessions.Update("revokedAt", now).Error; err != nil {
+			return err
+		}
+		return tokens.Updates(revokedBySession(now)).Error
+	})
+}
+
+// revokedBySession - kolom refresh token yang di-revoke karena session-nya di-revoke
+func revokedBySession(at time.Time) map[string]interface{} {
+	return map[string]interface{}{
+		"revokedAt":     at,
+		"revokedReason": models.RefreshTokenRevokedSession,
+	}
+}
+
+func (r *GormSessionRepository) FindActiveByUserID(userID string) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.
+		Where("\"userId\" = ? AND \"revokedAt\" IS NULL", userID).
+		Order("\"lastSeenAt\" DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// ==================== MEMORY REPOSITORY ====================
+
+// MemorySessionRepository - SessionRepository di memory, untuk unit test tanpa database
+// Refresh token milik session yang di-revoke ikut di-revoke di refreshTokens
+type MemorySessionRepository struct {
+	mu            sync.Mutex
+	sessions      map[string]models.Session
+	refreshTokens *MemoryRefreshTokenRepository
+}
+
+func NewMemorySessionRepository(refreshTokens *MemoryRefreshTokenRepository) *MemorySessionRepository {
+	return &MemorySessionRepository{
+		sessions:      make(map[string]models.Session),
+		refreshTokens: refreshTokens,
+	}
+}
+
+func (r *MemorySessionRepository) Create(session *models.Session) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.sessions {
+		if existing.JTI == session.JTI {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	if session.ID == "" {
+		session.ID = newMemoryID()
+	}
+	if session.CreatedAt.IsZero() {
+		session.CreatedAt = time.Now()
+	}
+	r.sessions[session.ID] = *session
+	return nil
+}
+
+func (r *MemorySessionRepository) FindByID(id string) (*models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	session, ok := r.sessions[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &session, nil
+}
+
+func (r *MemorySessionRepository) UpdateJTI(id, jti string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[id]; ok && session.RevokedAt == nil {
+		session.JTI = jti
+		session.LastSeenAt = time.Now()
+		r.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *MemorySessionRepository) Touch(id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if session, ok := r.sessions[id]; ok {
+		session.LastSeenAt = time.Now()
+		r.sessions[id] = session
+	}
+	return nil
+}
+
+func (r *MemorySessionRepository) Revoke(id string) error {
+	return r.revokeWhere(func(session models.Session) bool {
+		return session.ID == id
+	}, func(token models.RefreshToken) bool {
+		return token.SessionID == id
+	})
+}
+
+func (r *MemorySessionRepository) RevokeUserSessions(userID, exceptID string) error {
+	return r.revokeWhere(func(session models.Session) bool {
+		return session.UserID == userID && (exceptID == "" || session.ID != exceptID)
+	}, func(token models.RefreshToken) bool {
+		return token.UserID == userID && (exceptID == "" || token.SessionID != exceptID)
+	})
+}
+
+func (r *MemorySessionRepository) FindActiveByUserID(userID string) ([]models.Session, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var sessions []models.Session
+	for _, session := range r.sessions {
+		if session.UserID == userID && session.RevokedAt == nil {
+			sessions = append(sessions, session)
+		}
+	}
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastSeenAt.After(sessions[j].LastSeenAt)
+	})
+	return sessions, nil
+}
+
+// revokeWhere - revoke session dan refresh token yang belum di-revoke dan cocok dengan filter
+func (r *MemorySessionRepository) revokeWhere(sessionMatch func(models.Session) bool, tokenMatch func(models.RefreshToken) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, session := range r.sessions {
+		if session.RevokedAt == nil && sessionMatch(session) {
+			session.RevokedAt = &now
+			r.sessions[id] = session
+		}
+	}
+
+	if r.refreshTokens != nil {
+		r.refreshTokens.revokeWhere(tokenMatch, now, models.RefreshTokenRevokedSession)
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package repositories
+
+import (
+	"belajar-go-fiber/models"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// SigningKeyRepository - key pair JWT (lihat models.SigningKey)
+// Implementasi: GormSigningKeyRepository (production) dan MemorySigningKeyRepository (unit test tanpa database)
+type SigningKeyRepository interface {
+	// ListUsable - key yang belum expired (termasuk yang belum aktif), urut ActivatesAt terbaru dulu
+	ListUsable(now time.Time) ([]models.SigningKey, error)
+	// Rotate - simpan key baru jika key terbaru diaktifkan sebelum rotateBefore, algoritmanya berbeda, atau belum ada key.
+	// Key lama diberi ExpiresAt = key.ActivatesAt + grace. Return false jika instance lain sudah merotasi lebih dulu
+	Rotate(key *models.SigningKey, rotateBefore time.Time, grace time.Duration) (bool, error)
+	// DeleteExpired - hapus key yang sudah lewat ExpiresAt
+	DeleteExpired(now time.Time) (int64, error)
+}
+
+// needsRotation - aturan Rotate, dipakai kedua implementasi
+func needsRotation(current *models.SigningKey, algorithm string, rotateBefore time.Time) bool {
+	return current == nil || current.Algorithm != algorithm || current.ActivatesAt.Before(rotateBefore)
+}
+
+// ==================== GORM REPOSITORY ====================
+
+// GormSigningKeyRepository - SigningKeyRepository di tabel jwt_signing_keys lewat GORM
+type GormSigningKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewGormSigningKeyRepository(db *gorm.DB) *GormSigningKeyRepository {
+	return &GormSigningKeyRepository{db: db}
+}
+
+func (r *GormSigningKeyRepository) ListUsable(now time.Time) ([]models.SigningKey, error) {
+	var keys []models.SigningKey
+	err := r.db.
+		Where("\"expiresAt\" IS NULL OR \"expiresAt\" > ?", now).
+		Order("\"activatesAt\" DESC").
+		Find(&keys).Error
+	return keys, err
+}
+
+func (r *GormSigningKeyRepository) Rotate(key *models.SigningKey, rotateBefore time.Time, grace time.Duration) (bool, error) {
+	rotated := false
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Lock tabel supaya beberapa instance yang start / rotasi bersamaan tidak membuat key masing-masing
+		if err := tx.Exec("LOCK TABLE jwt_signing_keys IN SHARE ROW EXCLUSIVE MODE").Error; err != nil {
+			return err
+		}
+
+		var current []models.SigningKey
+		err := tx.Where("\"expiresAt\" IS NULL").
+			Order("\"activatesAt\" DESC").
+			Limit(1).
+			Find(&current).Error
+		if err != nil {
+			return err
+		}
+
+		var latest *models.SigningKey
+		if len(current) > 0 {
+			latest = &current[0]
+		}
+		if !needsRotation(latest, key.Algorithm, rotateBefore) {
+			return nil
+		}
+
+		err = tx.Model(&models.SigningKey{}).
+			Where("\"expiresAt\" IS NULL").
+			Update("expiresAt", key.ActivatesAt.Add(grace)).Error
+		if err != nil {
+			return err
+		}
+
+		if err := tx.Create(key).Error; err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+func (r *GormSigningKeyRepository) DeleteExpired(now time.Time) (int64, error) {
+	result := r.db.Where("\"expiresAt\" <= ?", now).Delete(&models.SigningKey{})
+	return result.RowsAffected, result.Error
+}
+
+// ==================== MEMORY REPOSITORY ====================
+
+// MemorySigningKeyRepository - SigningKeyRepository di memory, untuk unit test tanpa database
+type MemorySigningKeyRepository struct {
+	mu   sync.Mutex
+	keys map[string]models.SigningKey
+}
+
+func NewMemorySigningKeyRepository() *MemorySigningKeyRepository {
+	return &MemorySigningKeyRepository{keys: make(map[string]models.SigningKey)}
+}
+
+func (r *MemorySigningKeyRepository) ListUsable(now time.Time) ([]models.SigningKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.sorted(func(key models.SigningKey) bool {
+		return key.ExpiresAt == nil || key.ExpiresAt.After(now)
+	}), nil
+}
+
+func (r *MemorySigningKeyRepository) Rotate(key *models.SigningKey, rotateBefore time.Time, grace time.Duration) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current := r.sorted(func(key models.SigningKey) bool {
+		return key.ExpiresAt == nil
+	})
+
+	var latest *models.SigningKey
+	if len(current) > 0 {
+		latest = &current[0]
+	}
+	if !needsRotation(latest, key.Algorithm, rotateBefore) {
+		return false, nil
+	}
+
+	expiresAt := key.ActivatesAt.Add(grace)
+	for _, old := range current {
+		old.ExpiresAt = &expiresAt
+		r.keys[old.ID] = old
+	}
+
+	if key.ID == "" {
+		key.ID = newMemoryID()
+	}
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now()
+	}
+	r.keys[key.ID] = *key
+	return true, nil
+}
+
+func (r *MemorySigningKeyRepository) DeleteExpired(now time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, key := range r.keys {
+		if key.ExpiresAt != nil && !key.ExpiresAt.After(now) {
+			delete(r.keys, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+// sorted - salinan key yang lolos filter, urut ActivatesAt terbaru dulu
+func (r *MemorySigningKeyRepository) sorted(filter func(models.SigningKey) bool) []models.SigningKey {
+	var keys []models.SigningKey
+	for _, key := range r.keys {
+		if filter(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].ActivatesAt.After(keys[j].ActivatesAt)
+	})
+	return keys
+}
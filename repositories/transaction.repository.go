@@ -0,0 +1,53 @@
+package repositories
+
+import "gorm.io/gorm"
+
+// TxRepositories - repository yang terikat ke satu transaksi (lihat Transactor)
+type TxRepositories struct {
+	Users  UserRepository
+	Outbox EmailOutboxRepository
+}
+
+// Transactor - jalankan beberapa operasi repository dalam satu transaksi database,
+// contoh: simpan user + token verifikasi + email verifikasi di outbox sekaligus
+// Implementasi: GormTransactor (production) dan MemoryTransactor (unit test tanpa database)
+type Transactor interface {
+	// Transaction - commit jika fn return nil, rollback jika fn return error (error fn dikembalikan apa adanya)
+	Transaction(fn func(repos TxRepositories) error) error
+}
+
+// ==================== GORM TRANSACTOR ====================
+
+// GormTransactor - transaksi PostgreSQL lewat GORM
+type GormTransactor struct {
+	db *gorm.DB
+}
+
+func NewGormTransactor(db *gorm.DB) *GormTransactor {
+	return &GormTransactor{db: db}
+}
+
+func (t *GormTransactor) Transaction(fn func(repos TxRepositories) error) error {
+	return t.db.Transaction(func(tx *gorm.DB) error {
+		return fn(TxRepositories{
+			Users:  NewGormUserRepository(tx),
+			Outbox: NewGormEmailOutboxRepository(tx),
+		})
+	})
+}
+
+// ==================== MEMORY TRANSACTOR ====================
+
+// MemoryTransactor - memakai repository memory langsung
+// Tidak ada rollback: perubahan sebelum fn return error tetap tersimpan
+type MemoryTransactor struct {
+	repos TxRepositories
+}
+
+func NewMemoryTransactor(users *MemoryUserRepository, outbox *MemoryEmailOutboxRepository) *MemoryTransactor {
+	return &MemoryTransactor{repos: TxRepositories{Users: users, Outbox: outbox}}
+}
+
+func (t *MemoryTransactor) Transaction(fn func(repos TxRepositories) error) error {
+	return fn(t.repos)
+}
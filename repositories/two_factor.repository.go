@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"belajar-go-fiber/models"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository - secret TOTP (kolom twoFactor* di tabel users) dan recovery code 2FA
+// Implementasi: GormTwoFactorRepository (production) dan MemoryTwoFactorRepository (unit test tanpa database)
+type TwoFactorRepository interface {
+	// SaveSecret - simpan secret TOTP yang belum dikonfirmasi (2FA belum aktif)
+	SaveSecret(userID, secret string) error
+	// UpdateSecret - ganti secret TOTP tanpa mengubah status 2FA (enkripsi ulang secret lama)
+	UpdateSecret(userID, secret string) error
+	// Enable - aktifkan 2FA dan ganti semua recovery code lama dengan yang baru
+	Enable(userID string, step int64, codes []models.RecoveryCode) error
+	// Disable - nonaktifkan 2FA, hapus secret dan recovery code
+	Disable(userID string) error
+	// UseStep - tandai time-step TOTP sudah dipakai
+	// Return false jika step yang sama (atau lebih baru) sudah pernah dipakai → kode replay
+	UseStep(userID string, step int64) (bool, error)
+	// ConsumeRecoveryCode - pakai recovery code (sekali pakai)
+	// Return false jika kode tidak ditemukan atau sudah dipakai
+	ConsumeRecoveryCode(userID, codeHash string) (bool, error)
+}
+
+// ==================== GORM REPOSITORY ====================
+
+// GormTwoFactorRepository - TwoFactorRepository di tabel users dan recovery_codes lewat GORM
+type GormTwoFactorRepository struct {
+	db *gorm.DB
+}
+
+func NewGormTwoFactorRepository(db *gorm.DB) *GormTwoFactorRepository {
+	return &GormTwoFactorRepository{db: db}
+}
+
+func (r *GormTwoFactorRepository) SaveSecret(userID, secret string) error {
+	return r.db.Model(&models.Users{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"twoFactorSecret":   secret,
+			"twoFactorEnabled":  false,
+			"twoFactorLastStep": 0,
+		}).Error
+}
+
+func (r *GormTwoFactorRepository) UpdateSecret(userID, secret string) error {
+	return r.db.Model(&models.Users{}).
+		Where("id = ?", userID).
+		Update("twoFactorSecret", secret).Error
+}
+
+func (r *GormTwoFactorRepository) Enable(userID string, step int64, codes []models.RecoveryCode) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Users{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"twoFactorEnabled":  true,
+				"twoFactorLastStep": step,
+			}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Where("\"userId\" = ?", userID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&codes).Error
+	})
+}
+
+func (r *GormTwoFactorRepository) Disable(userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.Users{}).
+			Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"twoFactorEnabled":  false,
+				"twoFactorSecret":   "",
+				"twoFactorLastStep": 0,
+			}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("\"userId\" = ?", userID).Delete(&models.RecoveryCode{}).Error
+	})
+}
+
+func (r *GormTwoFactorRepository) UseStep(userID string, step int64) (bool, error) {
+	result := r.db.Model(&models.Users{}).
+		Where("id = ? AND \"twoFactorLastStep\" < ?", userID, step).
+		Update("twoFactorLastStep", step)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+func (r *GormTwoFactorRepository) ConsumeRecoveryCode(userID, codeHash string) (bool, error) {
+	result := r.db.Model(&models.RecoveryCode{}).
+		Where("\"userId\" = ? AND \"codeHash\" = ? AND \"usedAt\" IS NULL", userID, codeHash).
+		Update("usedAt", time.Now())
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ==================== MEMORY REPOSITORY ====================
+
+// MemoryTwoFactorRepository - TwoFactorRepository di memory, untuk unit test tanpa database
+// Kolom twoFactor* disimpan langsung di user milik users
+type MemoryTwoFactorRepository struct {
+	mu    sync.Mutex
+	users *MemoryUserRepository
+	codes map[string][]models.RecoveryCode // key = userID
+}
+
+func NewMemoryTwoFactorRepository(users *MemoryUserRepository) *MemoryTwoFactorRepository {
+	return &MemoryTwoFactorRepository{users: users, codes: make(map[string][]models.RecoveryCode)}
+}
+
+func (r *MemoryTwoFactorRepository) SaveSecret(userID, secret string) error {
+	return r.users.update(userID, func(user *models.Users) {
+		user.TwoFactorSecret = secret
+		user.TwoFactorEnabled = false
+		user.TwoFactorLastStep = 0
+	})
+}
+
+func (r *MemoryTwoFactorRepository) UpdateSecret(userID, secret string) error {
+	return r.users.update(userID, func(user *models.Users) {
+		user.TwoFactorSecret = secret
+	})
+}
+
+func (r *MemoryTwoFactorRepository) Enable(userID string, step int64, codes []models.RecoveryCode) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.users.update(userID, func(user *models.Users) {
+		user.TwoFactorEnabled = true
+		user.TwoFactorLastStep = step
+	})
+	if err != nil {
+		return err
+	}
+
+	r.codes[userID] = append([]models.RecoveryCode(nil), codes...)
+	return nil
+}
+
+func (r *MemoryTwoFactorRepository) Disable(userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	err := r.users.update(userID, func(user *models.Users) {
+		user.TwoFactorEnabled = false
+		user.TwoFactorSecret = ""
+		user.TwoFactorLastStep = 0
+	})
+	if err != nil {
+		return err
+	}
+
+	delete(r.codes, userID)
+	return nil
+}
+
+func (r *MemoryTwoFactorRepository) UseStep(userID string, step int64) (bool, error) {
+	fresh := false
+	err := r.users.update(userID, func(user *models.Users) {
+		if user.TwoFactorLastStep < step {
+			user.TwoFactorLastStep = step
+			fresh = true
+		}
+	})
+	return fresh, err
+}
+
+func (r *MemoryTwoFactorRepository) ConsumeRecoveryCode(userID, codeHash string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, code := range r.codes[userID] {
+		if code.CodeHash == codeHash && code.UsedAt == nil {
+			now := time.Now()
+			r.codes[userID][i].UsedAt = &now
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -1,23 +1,81 @@
 package repositories
 
 import (
-	"belajar-go-fiber/config"
 	"belajar-go-fiber/models"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"gorm.io/gorm"
 )
 
-func VerifyUserByEmail(email string) error {
-	return config.DB.Model(&models.Users{}).
-		Where("email = ?", email).
-		Updates(map[string]interface{}{
-			"activeUser":        true,
-			"verificationToken": "true",
-		}).Error
+// Error unique index users (username case-insensitive / nomor HP), dikembalikan Create dan UpdateInactiveUser
+// Cek IsUserNameTaken / IsPhoneRegistered sebelum insert tidak cukup untuk registrasi yang berjalan bersamaan
+var (
+	ErrUserNameTaken = errors.New("username already taken")
+	ErrPhoneTaken    = errors.New("phone number already registered")
+)
+
+// UserRepository - akses data user dan token email milik user (verifikasi email, reset password)
+// Implementasi: GormUserRepository (production) dan MemoryUserRepository (unit test tanpa database)
+// Method Find* return gorm.ErrRecordNotFound jika user / token tidak ada
+type UserRepository interface {
+	FindByID(id string) (*models.Users, error)
+	FindByEmail(email string) (*models.Users, error)
+	// FindByUserName - case-insensitive
+	FindByUserName(username string) (*models.Users, error)
+	// FindByPhone - phones berisi semua variasi format nomor yang sama
+	FindByPhone(phones []string) (*models.Users, error)
+	IsEmailRegistered(email string) (bool, error)
+	// IsUserNameTaken / IsPhoneRegistered - exceptEmail = akun yang sedang re-register
+	IsUserNameTaken(username, exceptEmail string) (bool, error)
+	IsPhoneRegistered(phones []string, exceptEmail string) (bool, error)
+	Create(user *models.Users) error
+	UpdateInactiveUser(email string, user *models.Users) error
+	UpdateVerificationSentAt(userID string, sentAt time.Time) error
+	UpdatePassword(userID, hashedPassword string) error
+	UpdateLocale(userID, locale string) error
+
+	// Admin
+	List(query models.AdminUserQuery) ([]models.Users, int64, error)
+	SetActive(userID string, active bool, at time.Time) error
+	// MarkEmailVerified - verifikasi email oleh admin (tanpa link email), token verifikasi yang belum dipakai dibatalkan
+	// gorm.ErrRecordNotFound jika email sudah diverifikasi
+	MarkEmailVerified(userID string, at time.Time) error
+	UpdateRole(userID, role string) error
+	// Delete - loginAttemptKey = key counter gagal login milik user (tabel login_attempts), ikut dihapus
+	Delete(userID, loginAttemptKey string) error
+
+	// Token email (tabel user_tokens)
+	CreateToken(token *models.UserToken) error
+	FindTokenByHash(purpose, tokenHash string) (*models.UserToken, error)
+	InvalidateTokens(userID, purpose string) error
+	// ConsumeToken - tandai token sudah dipakai, gorm.ErrRecordNotFound jika sudah dipakai request lain
+	ConsumeToken(tokenID string) error
+	// VerifyEmailWithToken / ResetPasswordWithToken - pakai token dan update user secara atomic,
+	// gorm.ErrRecordNotFound jika token sudah dipakai (atau akun dinonaktifkan untuk verifikasi email)
+	VerifyEmailWithToken(tokenID, userID string) error
+	ResetPasswordWithToken(tokenID, userID, hashedPassword string) error
+	// DeleteStaleTokens - hapus token yang expired sebelum cutoff (sudah dipakai / dibatalkan atau belum), return jumlah yang dihapus
+	DeleteStaleTokens(cutoff time.Time) (int64, error)
+}
+
+// ==================== GORM REPOSITORY ====================
+
+// GormUserRepository - UserRepository di PostgreSQL lewat GORM
+type GormUserRepository struct {
+	db *gorm.DB
+}
+
+func NewGormUserRepository(db *gorm.DB) *GormUserRepository {
+	return &GormUserRepository{db: db}
 }
 
 // Find user by email
-func FindUserByEmail(email string) (*models.Users, error) {
+func (r *GormUserRepository) FindByEmail(email string) (*models.Users, error) {
 	var user models.Users
-	err := config.DB.Where("email = ?", email).First(&user).Error
+	err := r.db.Where("email = ?", email).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
@@ -25,14 +83,14 @@ func FindUserByEmail(email string) (*models.Users, error) {
 }
 
 // Create user in DB
-func CreateUser(user *models.Users) error {
-	return config.DB.Create(user).Error
+func (r *GormUserRepository) Create(user *models.Users) error {
+	return translateUserUniqueError(r.db.Create(user).Error)
 }
 
 // Check if email already exists
-func IsEmailRegistered(email string) (bool, error) {
+func (r *GormUserRepository) IsEmailRegistered(email string) (bool, error) {
 	var count int64
-	err := config.DB.Model(&models.Users{}).
+	err := r.db.Model(&models.Users{}).
 		Where("email = ?", email).
 		Count(&count).Error
 
@@ -42,41 +100,238 @@ func IsEmailRegistered(email string) (bool, error) {
 	return count > 0, nil
 }
 
-// Find user by email with active status
-func FindUserByEmailWithActiveStatus(email string) (*models.Users, error) {
+// Update inactive user (re-register case)
+func (r *GormUserRepository) UpdateInactiveUser(email string, user *models.Users) error {
+	err := r.db.Model(&models.Users{}).
+		Where("email = ? AND \"activeUser\" = ? AND \"deactivatedAt\" IS NULL", email, false).
+		Updates(map[string]interface{}{
+			"userName":           user.UserName,
+			"noHandphone":        user.NoHandphone,
+			"password":           user.Password,
+			"locale":             user.Locale,
+			"verificationSentAt": user.VerificationSentAt,
+		}).Error
+	return translateUserUniqueError(err)
+}
+
+// translateUserUniqueError - unique violation (23505) pada index username / nomor HP (migration 0013)
+// jadi ErrUserNameTaken / ErrPhoneTaken, error lain dikembalikan apa adanya
+func translateUserUniqueError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) || pgErr.Code != "23505" {
+		return err
+	}
+
+	switch pgErr.ConstraintName {
+	case "idx_users_user_name_lower_unique":
+		return ErrUserNameTaken
+	case "idx_users_no_handphone_unique":
+		return ErrPhoneTaken
+	}
+	return err
+}
+
+// Find user by ID
+func (r *GormUserRepository) FindByID(id string) (*models.Users, error) {
 	var user models.Users
-	err := config.DB.Where("email = ?", email).First(&user).Error
+	err := r.db.Where("id = ?", id).First(&user).Error
 	if err != nil {
 		return nil, err
 	}
 	return &user, nil
 }
 
-// Update inactive user (re-register case)
-func UpdateInactiveUser(email string, user *models.Users) error {
-	return config.DB.Model(&models.Users{}).
-		Where("email = ? AND \"activeUser\" = ?", email, false).
-		Updates(map[string]interface{}{
-			"userName":          user.UserName,
-			"noHandphone":       user.NoHandphone,
-			"password":          user.Password,
-			"verificationToken": user.VerificationToken,
-		}).Error
+// Find user by username (case-insensitive)
+func (r *GormUserRepository) FindByUserName(username string) (*models.Users, error) {
+	var user models.Users
+	err := r.db.Where("LOWER(\"userName\") = LOWER(?)", username).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Find user by nomor HP (phones berisi semua variasi format nomor yang sama)
+func (r *GormUserRepository) FindByPhone(phones []string) (*models.Users, error) {
+	var user models.Users
+	err := r.db.Where("\"noHandphone\" IN ?", phones).First(&user).Error
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// Check if username already used by another account (exceptEmail = akun yang sedang re-register)
+func (r *GormUserRepository) IsUserNameTaken(username, exceptEmail string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Users{}).
+		Where("LOWER(\"userName\") = LOWER(?) AND email <> ?", username, exceptEmail).
+		Count(&count).Error
+
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// Check if phone number already used by another account (exceptEmail = akun yang sedang re-register)
+func (r *GormUserRepository) IsPhoneRegistered(phones []string, exceptEmail string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Users{}).
+		Where("\"noHandphone\" IN ? AND email <> ?", phones, exceptEmail).
+		Count(&count).Error
+
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
 }
 
-// SaveResetPasswordToken - Save reset password token ke field verificationToken
-func SaveResetPasswordToken(userID, token string) error {
-	return config.DB.Model(&models.Users{}).
+// UpdateVerificationSentAt - catat waktu email verifikasi terakhir dikirim (cooldown resend verification)
+func (r *GormUserRepository) UpdateVerificationSentAt(userID string, sentAt time.Time) error {
+	return r.db.Model(&models.Users{}).
 		Where("id = ?", userID).
-		Update("verificationToken", token).Error
+		Update("verificationSentAt", sentAt).Error
 }
 
-// UpdatePasswordAndClearToken - Update password dan clear reset token
-func UpdatePasswordAndClearToken(userID, hashedPassword string) error {
-	return config.DB.Model(&models.Users{}).
+// UpdatePassword - update password user (change password)
+func (r *GormUserRepository) UpdatePassword(userID, hashedPassword string) error {
+	return r.db.Model(&models.Users{}).
 		Where("id = ?", userID).
-		Updates(map[string]interface{}{
-			"password":          hashedPassword,
-			"verificationToken": "true", // Clear token setelah reset
-		}).Error
+		Update("password", hashedPassword).Error
+}
+
+// UpdateLocale - ganti bahasa email user
+func (r *GormUserRepository) UpdateLocale(userID, locale string) error {
+	return r.db.Model(&models.Users{}).
+		Where("id = ?", userID).
+		Update("locale", locale).Error
+}
+
+// ==================== ADMIN ====================
+
+// userSortColumns - kolom yang boleh dipakai untuk sorting list user (key = nama field di query ?sort=)
+var userSortColumns = map[string]string{
+	"createdAt": "\"createdAt\"",
+	"userName":  "LOWER(\"userName\")",
+	"email":     "email",
+}
+
+// IsValidUserSort - cek nama field sort (tanpa prefix "-") didukung ListUsers
+func IsValidUserSort(field string) bool {
+	_, ok := userSortColumns[field]
+	return ok
+}
+
+// List - list user dengan filter, search, sorting dan pagination
+// query.Page dan query.PageSize harus sudah dinormalisasi (>= 1) oleh service
+func (r *GormUserRepository) List(query models.AdminUserQuery) ([]models.Users, int64, error) {
+	db := r.db.Model(&models.Users{})
+
+	if search := strings.TrimSpace(query.Search); search != "" {
+		pattern := "%" + escapeLike(search) + "%"
+		db = db.Where("\"userName\" ILIKE ? OR email ILIKE ? OR \"noHandphone\" ILIKE ?", pattern, pattern, pattern)
+	}
+	if query.Role != "" {
+		db = db.Where("role = ?", query.Role)
+	}
+	if query.Active != "" {
+		db = db.Where("\"activeUser\" = ?", query.Active == "true")
+	}
+
+	var total int64
+	if err := db.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	field, direction := strings.TrimPrefix(query.Sort, "-"), "ASC"
+	if strings.HasPrefix(query.Sort, "-") {
+		direction = "DESC"
+	}
+	column, ok := userSortColumns[field]
+	if !ok {
+		column, direction = userSortColumns["createdAt"], "DESC"
+	}
+
+	var users []models.Users
+	err := db.Order(column + " " + direction).
+		Order("id").
+		Offset((query.Page - 1) * query.PageSize).
+		Limit(query.PageSize).
+		Find(&users).Error
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// escapeLike - escape karakter wildcard LIKE (% dan _) dari input user
+func escapeLike(value string) string {
+	return strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(value)
+}
+
+// SetActive - aktifkan / nonaktifkan akun (admin)
+// Nonaktif: activeUser=false dan deactivatedAt diisi supaya tidak bisa diaktifkan lagi lewat verifikasi / register ulang
+// Aktif: activeUser hanya true jika email sudah diverifikasi
+func (r *GormUserRepository) SetActive(userID string, active bool, at time.Time) error {
+	updates := map[string]interface{}{
+		"activeUser":    gorm.Expr("\"emailVerifiedAt\" IS NOT NULL"),
+		"deactivatedAt": nil,
+	}
+	if !active {
+		updates = map[string]interface{}{
+			"activeUser":    false,
+			"deactivatedAt": at,
+		}
+	}
+
+	return r.db.Model(&models.Users{}).
+		Where("id = ?", userID).
+		Updates(updates).Error
+}
+
+// UpdateRole - ganti role user (admin)
+func (r *GormUserRepository) UpdateRole(userID, role string) error {
+	return r.db.Model(&models.Users{}).
+		Where("id = ?", userID).
+		Update("role", role).Error
+}
+
+// MarkEmailVerified - verifikasi email oleh admin, akun yang dinonaktifkan tetap nonaktif
+func (r *GormUserRepository) MarkEmailVerified(userID string, at time.Time) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.Users{}).
+			Where("id = ? AND \"emailVerifiedAt\" IS NULL", userID).
+			Updates(map[string]interface{}{
+				"emailVerifiedAt": at,
+				"activeUser":      gorm.Expr("\"deactivatedAt\" IS NULL"),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return tx.Model(&models.UserToken{}).
+			Where("\"userId\" = ? AND purpose = ? AND \"consumedAt\" IS NULL", userID, models.TokenPurposeEmailVerification).
+			Update("consumedAt", at).Error
+	})
+}
+
+// Delete - hapus user beserta session, refresh token, recovery code, token email dan counter gagal login miliknya
+func (r *GormUserRepository) Delete(userID, loginAttemptKey string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, model := range []interface{}{&models.RefreshToken{}, &models.Session{}, &models.RecoveryCode{}, &models.UserToken{}} {
+			if err := tx.Where("\"userId\" = ?", userID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Where("key = ?", loginAttemptKey).Delete(&models.LoginAttempt{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Where("id = ?", userID).Delete(&models.Users{}).Error
+	})
 }
@@ -0,0 +1,436 @@
+package repositories
+
+import (
+	"belajar-go-fiber/models"
+	"crypto/rand"
+	"encoding/hex"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ==================== MEMORY REPOSITORY ====================
+
+// MemoryUserRepository - UserRepository di memory, untuk unit test service tanpa database
+// Data yang dikembalikan selalu salinan, perubahan di luar repository tidak ikut tersimpan
+type MemoryUserRepository struct {
+	mu     sync.Mutex
+	users  map[string]models.Users
+	tokens map[string]models.UserToken
+}
+
+func NewMemoryUserRepository() *MemoryUserRepository {
+	return &MemoryUserRepository{
+		users:  make(map[string]models.Users),
+		tokens: make(map[string]models.UserToken),
+	}
+}
+
+func (r *MemoryUserRepository) FindByID(id string) (*models.Users, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[id]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &user, nil
+}
+
+func (r *MemoryUserRepository) FindByEmail(email string) (*models.Users, error) {
+	return r.findFirst(func(user *models.Users) bool {
+		return user.Email == email
+	})
+}
+
+func (r *MemoryUserRepository) FindByUserName(username string) (*models.Users, error) {
+	return r.findFirst(func(user *models.Users) bool {
+		return strings.EqualFold(user.UserName, username)
+	})
+}
+
+func (r *MemoryUserRepository) FindByPhone(phones []string) (*models.Users, error) {
+	return r.findFirst(func(user *models.Users) bool {
+		return slices.Contains(phones, user.NoHandphone)
+	})
+}
+
+func (r *MemoryUserRepository) IsEmailRegistered(email string) (bool, error) {
+	return r.exists(func(user *models.Users) bool {
+		return user.Email == email
+	}), nil
+}
+
+func (r *MemoryUserRepository) IsUserNameTaken(username, exceptEmail string) (bool, error) {
+	return r.exists(func(user *models.Users) bool {
+		return strings.EqualFold(user.UserName, username) && user.Email != exceptEmail
+	}), nil
+}
+
+func (r *MemoryUserRepository) IsPhoneRegistered(phones []string, exceptEmail string) (bool, error) {
+	return r.exists(func(user *models.Users) bool {
+		return slices.Contains(phones, user.NoHandphone) && user.Email != exceptEmail
+	}), nil
+}
+
+func (r *MemoryUserRepository) Create(user *models.Users) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.users {
+		if existing.Email == user.Email {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+	if err := r.checkUnique(user, ""); err != nil {
+		return err
+	}
+
+	// Default kolom sama dengan tag gorm di models.Users
+	if user.ID == "" {
+		user.ID = newMemoryID()
+	}
+	if user.Role == "" {
+		user.Role = "user"
+	}
+	if user.Locale == "" {
+		user.Locale = "en"
+	}
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now()
+	}
+	r.users[user.ID] = *user
+	return nil
+}
+
+func (r *MemoryUserRepository) UpdateInactiveUser(email string, user *models.Users) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, existing := range r.users {
+		if existing.Email != email || existing.ActiveUser || existing.DeactivatedAt != nil {
+			continue
+		}
+		if err := r.checkUnique(user, id); err != nil {
+			return err
+		}
+		existing.UserName = user.UserName
+		existing.NoHandphone = user.NoHandphone
+		existing.Password = user.Password
+		existing.Locale = user.Locale
+		existing.VerificationSentAt = user.VerificationSentAt
+		r.users[id] = existing
+	}
+	return nil
+}
+
+// checkUnique - sama dengan unique index username (case-insensitive) dan nomor HP di PostgreSQL
+// exceptID = user yang sedang di-update, panggil dengan r.mu terkunci
+func (r *MemoryUserRepository) checkUnique(user *models.Users, exceptID string) error {
+	for id, existing := range r.users {
+		if id == exceptID {
+			continue
+		}
+		if user.UserName != "" && strings.EqualFold(existing.UserName, user.UserName) {
+			return ErrUserNameTaken
+		}
+		if user.NoHandphone != "" && existing.NoHandphone == user.NoHandphone {
+			return ErrPhoneTaken
+		}
+	}
+	return nil
+}
+
+func (r *MemoryUserRepository) UpdateVerificationSentAt(userID string, sentAt time.Time) error {
+	return r.update(userID, func(user *models.Users) {
+		user.VerificationSentAt = &sentAt
+	})
+}
+
+func (r *MemoryUserRepository) UpdatePassword(userID, hashedPassword string) error {
+	return r.update(userID, func(user *models.Users) {
+		user.Password = hashedPassword
+	})
+}
+
+func (r *MemoryUserRepository) UpdateLocale(userID, locale string) error {
+	return r.update(userID, func(user *models.Users) {
+		user.Locale = locale
+	})
+}
+
+func (r *MemoryUserRepository) List(query models.AdminUserQuery) ([]models.Users, int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	search := strings.ToLower(strings.TrimSpace(query.Search))
+	var users []models.Users
+	for _, user := range r.users {
+		if search != "" &&
+			!strings.Contains(strings.ToLower(user.UserName), search) &&
+			!strings.Contains(strings.ToLower(user.Email), search) &&
+			!strings.Contains(strings.ToLower(user.NoHandphone), search) {
+			continue
+		}
+		if query.Role != "" && user.Role != query.Role {
+			continue
+		}
+		if query.Active != "" && user.ActiveUser != (query.Active == "true") {
+			continue
+		}
+		users = append(users, user)
+	}
+
+	field, desc := strings.TrimPrefix(query.Sort, "-"), strings.HasPrefix(query.Sort, "-")
+	if !IsValidUserSort(field) {
+		field, desc = "createdAt", true
+	}
+	sort.Slice(users, func(i, j int) bool {
+		a, b := users[i], users[j]
+		if desc {
+			a, b = b, a
+		}
+		switch field {
+		case "userName":
+			if !strings.EqualFold(a.UserName, b.UserName) {
+				return strings.ToLower(a.UserName) < strings.ToLower(b.UserName)
+			}
+		case "email":
+			if a.Email != b.Email {
+				return a.Email < b.Email
+			}
+		default:
+			if !a.CreatedAt.Equal(b.CreatedAt) {
+				return a.CreatedAt.Before(b.CreatedAt)
+			}
+		}
+		return users[i].ID < users[j].ID
+	})
+
+	return paginate(users, query.Page, query.PageSize), int64(len(users)), nil
+}
+
+func (r *MemoryUserRepository) SetActive(userID string, active bool, at time.Time) error {
+	return r.update(userID, func(user *models.Users) {
+		user.ActiveUser, user.DeactivatedAt = user.EmailVerifiedAt != nil, nil
+		if !active {
+			user.ActiveUser, user.DeactivatedAt = false, &at
+		}
+	})
+}
+
+func (r *MemoryUserRepository) MarkEmailVerified(userID string, at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.EmailVerifiedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	user.EmailVerifiedAt = &at
+	user.ActiveUser = user.DeactivatedAt == nil
+	r.users[userID] = user
+
+	for id, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == models.TokenPurposeEmailVerification && token.ConsumedAt == nil {
+			token.ConsumedAt = &at
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *MemoryUserRepository) UpdateRole(userID, role string) error {
+	return r.update(userID, func(user *models.Users) {
+		user.Role = role
+	})
+}
+
+// Delete - counter gagal login di test disimpan di MemoryLoginAttemptStore, loginAttemptKey tidak dipakai
+func (r *MemoryUserRepository) Delete(userID, loginAttemptKey string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, token := range r.tokens {
+		if token.UserID == userID {
+			delete(r.tokens, id)
+		}
+	}
+	delete(r.users, userID)
+	return nil
+}
+
+func (r *MemoryUserRepository) CreateToken(token *models.UserToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, existing := range r.tokens {
+		if existing.TokenHash == token.TokenHash {
+			return gorm.ErrDuplicatedKey
+		}
+	}
+
+	if token.ID == "" {
+		token.ID = newMemoryID()
+	}
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now()
+	}
+	r.tokens[token.ID] = *token
+	return nil
+}
+
+func (r *MemoryUserRepository) FindTokenByHash(purpose, tokenHash string) (*models.UserToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.tokens {
+		if token.Purpose == purpose && token.TokenHash == tokenHash {
+			return &token, nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *MemoryUserRepository) InvalidateTokens(userID, purpose string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for id, token := range r.tokens {
+		if token.UserID == userID && token.Purpose == purpose && token.ConsumedAt == nil {
+			token.ConsumedAt = &now
+			r.tokens[id] = token
+		}
+	}
+	return nil
+}
+
+func (r *MemoryUserRepository) ConsumeToken(tokenID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.consumeToken(tokenID)
+}
+
+func (r *MemoryUserRepository) DeleteStaleTokens(cutoff time.Time) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var deleted int64
+	for id, token := range r.tokens {
+		if token.ExpiresAt.Before(cutoff) {
+			delete(r.tokens, id)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (r *MemoryUserRepository) VerifyEmailWithToken(tokenID, userID string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok || user.DeactivatedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+	if err := r.consumeToken(tokenID); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	user.ActiveUser, user.EmailVerifiedAt = true, &now
+	r.users[userID] = user
+	return nil
+}
+
+func (r *MemoryUserRepository) ResetPasswordWithToken(tokenID, userID, hashedPassword string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.consumeToken(tokenID); err != nil {
+		return err
+	}
+
+	if user, ok := r.users[userID]; ok {
+		user.Password = hashedPassword
+		r.users[userID] = user
+	}
+	return nil
+}
+
+// consumeToken - sama dengan consumeUserToken, r.mu harus sudah di-lock
+func (r *MemoryUserRepository) consumeToken(tokenID string) error {
+	token, ok := r.tokens[tokenID]
+	if !ok || token.ConsumedAt != nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	now := time.Now()
+	token.ConsumedAt = &now
+	r.tokens[tokenID] = token
+	return nil
+}
+
+// findFirst - user pertama (urut createdAt) yang cocok dengan match
+func (r *MemoryUserRepository) findFirst(match func(user *models.Users) bool) (*models.Users, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var found *models.Users
+	for _, user := range r.users {
+		if match(&user) && (found == nil || user.CreatedAt.Before(found.CreatedAt)) {
+			found = &user
+		}
+	}
+	if found == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return found, nil
+}
+
+func (r *MemoryUserRepository) exists(match func(user *models.Users) bool) bool {
+	_, err := r.findFirst(match)
+	return err == nil
+}
+
+// update - ubah satu user by ID, tidak melakukan apa-apa jika user tidak ada (sama dengan UPDATE ... WHERE id = ?)
+func (r *MemoryUserRepository) update(userID string, apply func(user *models.Users)) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, ok := r.users[userID]
+	if !ok {
+		return nil
+	}
+	apply(&user)
+	r.users[userID] = user
+	return nil
+}
+
+// newMemoryID - ID acak 24 karakter hex, format sama dengan generate_object_id() di database
+func newMemoryID() string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(b)
+}
+
+// paginate - ambil satu halaman dari items, page dan pageSize >= 1
+func paginate[T any](items []T, page, pageSize int) []T {
+	start := (page - 1) * pageSize
+	if start >= len(items) {
+		return []T{}
+	}
+	end := start + pageSize
+	if end > len(items) {
+		end = len(items)
+	}
+	return items[start:end]
+}
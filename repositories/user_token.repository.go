@@ -0,0 +1,94 @@
+package repositories
+
+import (
+	"belajar-go-fiber/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// CreateToken - simpan token baru
+func (r *GormUserRepository) CreateToken(token *models.UserToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindTokenByHash - cari token berdasarkan purpose dan hash
+func (r *GormUserRepository) FindTokenByHash(purpose, tokenHash string) (*models.UserToken, error) {
+	var token models.UserToken
+	err := r.db.Where("purpose = ? AND \"tokenHash\" = ?", purpose, tokenHash).First(&token).Error
+	if err != nil {
+		return nil, err
+	}
+	return &token, nil
+}
+
+// InvalidateTokens - batalkan semua token user dengan purpose tertentu yang belum dipakai
+func (r *GormUserRepository) InvalidateTokens(userID, purpose string) error {
+	return r.db.Model(&models.UserToken{}).
+		Where("\"userId\" = ? AND purpose = ? AND \"consumedAt\" IS NULL", userID, purpose).
+		Update("consumedAt", time.Now()).Error
+}
+
+// ConsumeToken - pakai token tanpa mengubah data user (mis. jti mfaToken)
+func (r *GormUserRepository) ConsumeToken(tokenID string) error {
+	return consumeUserToken(r.db, tokenID)
+}
+
+// VerifyEmailWithToken - pakai token verifikasi dan aktifkan user dalam satu transaksi
+// Return gorm.ErrRecordNotFound jika token sudah dipakai atau akun sudah dinonaktifkan admin
+func (r *GormUserRepository) VerifyEmailWithToken(tokenID, userID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := consumeUserToken(tx, tokenID); err != nil {
+			return err
+		}
+
+		result := tx.Model(&models.Users{}).
+			Where("id = ? AND \"deactivatedAt\" IS NULL", userID).
+			Updates(map[string]interface{}{
+				"activeUser":      true,
+				"emailVerifiedAt": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// ResetPasswordWithToken - pakai token reset password dan simpan password baru dalam satu transaksi
+// Return gorm.ErrRecordNotFound jika token sudah dipakai
+func (r *GormUserRepository) ResetPasswordWithToken(tokenID, userID, hashedPassword string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := consumeUserToken(tx, tokenID); err != nil {
+			return err
+		}
+
+		return tx.Model(&models.Users{}).
+			Where("id = ?", userID).
+			Update("password", hashedPassword).Error
+	})
+}
+
+// DeleteStaleTokens - hapus token yang expired sebelum cutoff
+// Token yang sudah dipakai juga pasti expired (TTL pendek), jadi cukup cek expiresAt
+func (r *GormUserRepository) DeleteStaleTokens(cutoff time.Time) (int64, error) {
+	result := r.db.Where("\"expiresAt\" < ?", cutoff).Delete(&models.UserToken{})
+	return result.RowsAffected, result.Error
+}
+
+// consumeUserToken - tandai token sudah dipakai, gorm.ErrRecordNotFound jika sudah dipakai request lain
+func consumeUserToken(tx *gorm.DB, tokenID string) error {
+	result := tx.Model(&models.UserToken{}).
+		Where("id = ? AND \"consumedAt\" IS NULL", tokenID).
+		Update("consumedAt", time.Now())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
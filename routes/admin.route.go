@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"belajar-go-fiber/handlers"
+	"belajar-go-fiber/middlewares"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminRoutes - Admin user management, audit log dan dump konfigurasi (hanya role admin), semua aksi yang mengubah data dicatat di audit trail
+// Rate limit per admin (120 request/menit), override via env RATE_LIMIT_ADMIN_USER
+func AdminRoutes(router fiber.Router, h *handlers.AdminHandler, protect fiber.Handler) {
+	admin := []fiber.Handler{
+		protect,
+		LimitByUser("admin-user", 120, time.Minute),
+		middlewares.RequireRole("admin"),
+	}
+
+	router.Get("/admin/users", append(admin, h.ListUsersHandler)...)
+	router.Get("/admin/users/:id", append(admin, h.GetUserHandler)...)
+	router.Post("/admin/users/:id/activate", append(admin, h.ActivateUserHandler)...)
+	router.Post("/admin/users/:id/deactivate", append(admin, h.DeactivateUserHandler)...)
+	router.Post("/admin/users/:id/verify-email", append(admin, h.VerifyUserEmailHandler)...)
+	router.Put("/admin/users/:id/role", append(admin, h.ChangeUserRoleHandler)...)
+	router.Post("/admin/users/:id/force-password-reset", append(admin, h.ForcePasswordResetHandler)...)
+	router.Delete("/admin/users/:id", append(admin, h.DeleteUserHandler)...)
+	router.Get("/admin/audit-events", append(admin, h.ListAuditEventsHandler)...)
+	router.Get("/admin/config", append(admin, h.GetConfigHandler)...)
+}
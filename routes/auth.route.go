@@ -2,22 +2,60 @@ package routes
 
 import (
 	"belajar-go-fiber/handlers"
+	"belajar-go-fiber/middlewares"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// AuthRoutes - Public routes (Register, Login, Verify Email, Forgot Password, Reset Password)
-func AuthRoutes(app *fiber.App) {
-	app.Post("/auth/register", handlers.RegisterHandler)
-	app.Post("/auth/login", handlers.LoginHandler)
-	app.Get("/auth/verify", handlers.VerificationEmailHandler)
-	app.Post("/auth/forgot-password", handlers.ForgotPasswordHandler)
-	app.Post("/auth/reset-password", handlers.ResetPasswordHandler)
+// AuthRoutes - Public routes (Register, Login, Refresh, Verify Email, Resend Verification, Forgot Password, Reset Password)
+// Semua route dibatasi rate limit per IP (dan per email untuk route yang mengirim email),
+// default di bawah bisa di-override via env RATE_LIMIT_<NAME>, contoh RATE_LIMIT_REGISTER_IP="10/1h"
+func AuthRoutes(router fiber.Router, h *handlers.AuthHandler) {
+	router.Post("/auth/register", limitByIP("register-ip", 5, time.Hour), limitByEmail("register-email", 3, time.Hour), h.RegisterHandler)
+	router.Post("/auth/login", limitByIP("login-ip", 30, 15*time.Minute), h.LoginHandler)
+	router.Post("/auth/login/2fa", limitByIP("login-2fa-ip", 10, 5*time.Minute), h.TwoFactorLoginHandler)
+	router.Post("/auth/refresh", limitByIP("refresh-ip", 60, 15*time.Minute), h.RefreshHandler)
+	router.Get("/auth/verify", limitByIP("verify-ip", 20, 15*time.Minute), h.VerificationEmailHandler)
+	router.Post("/auth/resend-verification", limitByIP("resend-verification-ip", 5, time.Hour), limitByEmail("resend-verification-email", 3, time.Hour), h.ResendVerificationHandler)
+	router.Get("/auth/unlock", limitByIP("unlock-ip", 10, time.Hour), h.UnlockAccountHandler)
+	router.Post("/auth/forgot-password", limitByIP("forgot-password-ip", 5, time.Hour), limitByEmail("forgot-password-email", 3, time.Hour), h.ForgotPasswordHandler)
+	router.Post("/auth/reset-password", limitByIP("reset-password-ip", 10, time.Hour), h.ResetPasswordHandler)
 }
 
-// ProtectedRoutes - Function ini tidak digunakan lagi karena protected routes
-// sekarang didaftarkan langsung di main.go untuk menghindari konflik middleware
-func ProtectedRoutes(app fiber.Router) {
-	// DEPRECATED: Protected routes sekarang di main.go
-	// Ini dibiarkan untuk compatibility jika ada referensi lain
+// limitByIP - rate limit per IP address
+func limitByIP(name string, limit int, period time.Duration) fiber.Handler {
+	return middlewares.RateLimit(middlewares.RateLimitPolicy{Name: name, Limit: limit, Period: period, KeyBy: middlewares.KeyByIP})
+}
+
+// limitByEmail - rate limit per email di body request
+func limitByEmail(name string, limit int, period time.Duration) fiber.Handler {
+	return middlewares.RateLimit(middlewares.RateLimitPolicy{Name: name, Limit: limit, Period: period, KeyBy: middlewares.KeyByEmail})
+}
+
+// LimitByUser - rate limit per user yang sudah login (pasang setelah middlewares.ProtectRoute)
+func LimitByUser(name string, limit int, period time.Duration) fiber.Handler {
+	return middlewares.RateLimit(middlewares.RateLimitPolicy{Name: name, Limit: limit, Period: period, KeyBy: middlewares.KeyByUser})
+}
+
+// ProtectedRoutes - Protected routes (Me, Logout, Sessions, Change Password, Locale, 2FA), perlu authentication
+// Middleware (protect = middlewares.ProtectRoute) dipasang per route (bukan router.Use) supaya tidak ikut berlaku untuk public routes dengan prefix yang sama.
+// Rate limit per user (60 request/menit), override via env RATE_LIMIT_ACCOUNT_USER
+func ProtectedRoutes(router fiber.Router, h *handlers.AuthHandler, protect fiber.Handler) {
+	protected := []fiber.Handler{
+		protect,
+		LimitByUser("account-user", 60, time.Minute),
+		middlewares.RequireRole("admin", "user"),
+	}
+
+	router.Get("/auth/me", append(protected, handlers.MeHandler)...)
+	router.Post("/auth/logout", append(protected, h.LogoutHandler)...)
+	router.Get("/auth/sessions", append(protected, h.ListSessionsHandler)...)
+	router.Delete("/auth/sessions", append(protected, h.RevokeOtherSessionsHandler)...)
+	router.Delete("/auth/sessions/:id", append(protected, h.RevokeSessionHandler)...)
+	router.Post("/auth/change-password", append(protected, h.ChangePasswordHandler)...)
+	router.Put("/auth/me/locale", append(protected, h.UpdateLocaleHandler)...)
+	router.Post("/auth/2fa/setup", append(protected, h.SetupTwoFactorHandler)...)
+	router.Post("/auth/2fa/confirm", append(protected, h.ConfirmTwoFactorHandler)...)
+	router.Post("/auth/2fa/disable", append(protected, h.DisableTwoFactorHandler)...)
 }
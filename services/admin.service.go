@@ -0,0 +1,293 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/repositories"
+	"belajar-go-fiber/utils"
+	"errors"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ==================== ADMIN USER SERVICE ====================
+
+// AdminService - logic manajemen user dan audit log untuk admin
+type AdminService struct {
+	users repositories.UserRepository
+	audit repositories.AuditRepository
+	auth  *AuthService // transaksi, link reset password dan audit memakai repository yang sama
+}
+
+func NewAdminService(auth *AuthService) *AdminService {
+	return &AdminService{users: auth.users, audit: auth.audit, auth: auth}
+}
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// ErrSelfModification - admin tidak boleh menonaktifkan, menghapus, atau mengganti role akunnya sendiri
+// (mencegah sistem kehilangan admin terakhir)
+var ErrSelfModification = newError(KindForbidden, "self_modification_not_allowed", "admins cannot perform this action on their own account")
+
+var (
+	ErrEmailAlreadyVerified = newError(KindConflict, "email_already_verified", "email is already verified")
+	ErrUnverifiedActivation = newError(KindConflict, "email_not_verified", "email is not verified, verify the email instead of activating the account")
+)
+
+// normalizePage - page minimal 1, pageSize default 20 dan maksimal 100
+func normalizePage(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+	return page, pageSize
+}
+
+// ListUsers - list user untuk admin dengan filter, search dan pagination
+func (s *AdminService) ListUsers(query models.AdminUserQuery) (models.AdminUserListResponse, error) {
+	query.Page, query.PageSize = normalizePage(query.Page, query.PageSize)
+
+	validationErr := &utils.ValidationError{}
+	if query.Role != "" && query.Role != "user" && query.Role != "admin" {
+		validationErr.Add("role", "must be one of: user, admin")
+	}
+	if query.Active != "" && query.Active != "true" && query.Active != "false" {
+		validationErr.Add("active", "must be true or false")
+	}
+	if query.Sort != "" && !repositories.IsValidUserSort(strings.TrimPrefix(query.Sort, "-")) {
+		validationErr.Add("sort", "must be one of: createdAt, userName, email (prefix \"-\" for descending)")
+	}
+	if len(validationErr.Fields) > 0 {
+		return models.AdminUserListResponse{}, validationErr
+	}
+
+	users, total, err := s.users.List(query)
+	if err != nil {
+		return models.AdminUserListResponse{}, errDatabase(err)
+	}
+
+	result := make([]models.AdminUser, 0, len(users))
+	for i := range users {
+		result = append(result, toAdminUser(&users[i]))
+	}
+
+	return models.AdminUserListResponse{
+		Users:    result,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Total:    total,
+	}, nil
+}
+
+// GetUser - detail satu user untuk admin
+func (s *AdminService) GetUser(userID string) (models.AdminUser, error) {
+	user, err := s.findUserForAdmin(userID)
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+
+	return toAdminUser(user), nil
+}
+
+// SetUserActive - aktifkan / nonaktifkan akun
+// Akun yang dinonaktifkan langsung di-sign-out dari semua device
+// Mengaktifkan tidak memverifikasi email, akun yang belum verifikasi harus lewat VerifyUserEmail
+func (s *AdminService) SetUserActive(userID string, active bool, meta RequestMeta) (models.AdminUser, error) {
+	if meta.ActorID == userID && !active {
+		return models.AdminUser{}, ErrSelfModification
+	}
+
+	user, err := s.findUserForAdmin(userID)
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+
+	if active && user.DeactivatedAt == nil && user.EmailVerifiedAt == nil {
+		return models.AdminUser{}, ErrUnverifiedActivation
+	}
+
+	now := time.Now()
+	if err := s.users.SetActive(user.ID, active, now); err != nil {
+		return models.AdminUser{}, errDatabase(err)
+	}
+
+	action := AuditAdminUserActivated
+	user.ActiveUser, user.DeactivatedAt = user.EmailVerifiedAt != nil, nil
+	if !active {
+		if err := s.auth.RevokeAllSessions(user.ID, ""); err != nil {
+			return models.AdminUser{}, err
+		}
+
+		action = AuditAdminUserDeactivated
+		user.ActiveUser, user.DeactivatedAt = false, &now
+	}
+
+	s.auth.recordAudit(meta, action, user.ID, nil)
+
+	return toAdminUser(user), nil
+}
+
+// VerifyUserEmail - verifikasi email user oleh admin (contoh: user tidak menerima email verifikasi)
+// Akun yang sedang dinonaktifkan tetap nonaktif
+func (s *AdminService) VerifyUserEmail(userID string, meta RequestMeta) (models.AdminUser, error) {
+	user, err := s.findUserForAdmin(userID)
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+
+	if user.EmailVerifiedAt != nil {
+		return models.AdminUser{}, ErrEmailAlreadyVerified
+	}
+
+	now := time.Now()
+	if err := s.users.MarkEmailVerified(user.ID, now); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.AdminUser{}, ErrEmailAlreadyVerified
+		}
+		return models.AdminUser{}, errDatabase(err)
+	}
+
+	s.auth.recordAudit(meta, AuditAdminUserEmailVerified, user.ID, map[string]interface{}{
+		"email": user.Email,
+	})
+
+	user.EmailVerifiedAt, user.ActiveUser = &now, user.DeactivatedAt == nil
+	return toAdminUser(user), nil
+}
+
+// ChangeUserRole - ganti role user
+// Session user di-revoke karena role ikut tersimpan di auth_token
+func (s *AdminService) ChangeUserRole(userID, role string, meta RequestMeta) (models.AdminUser, error) {
+	if meta.ActorID == userID {
+		return models.AdminUser{}, ErrSelfModification
+	}
+
+	user, err := s.findUserForAdmin(userID)
+	if err != nil {
+		return models.AdminUser{}, err
+	}
+
+	if user.Role == role {
+		return toAdminUser(user), nil
+	}
+
+	if err := s.users.UpdateRole(user.ID, role); err != nil {
+		return models.AdminUser{}, errDatabase(err)
+	}
+
+	if err := s.auth.RevokeAllSessions(user.ID, ""); err != nil {
+		return models.AdminUser{}, err
+	}
+
+	s.auth.recordAudit(meta, AuditAdminUserRoleChanged, user.ID, map[string]interface{}{
+		"from": user.Role,
+		"to":   role,
+	})
+
+	user.Role = role
+	return toAdminUser(user), nil
+}
+
+// ForcePasswordReset - paksa user mengganti password
+// Password lama langsung tidak berlaku, semua session di-revoke, dan link reset password dikirim ke email user
+func (s *AdminService) ForcePasswordReset(userID string, meta RequestMeta) error {
+	user, err := s.findUserForAdmin(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.ActiveUser {
+		return newError(KindConflict, "user_not_active", "password reset can only be forced for active users")
+	}
+
+	// Ganti password dengan nilai acak yang tidak diketahui siapa pun
+	randomPassword, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return newInternalError("failed to generate password", err)
+	}
+	hashedPassword, err := HashPassword(randomPassword)
+	if err != nil {
+		return newInternalError("failed to hash password", err)
+	}
+
+	// Password acak dan email link reset password disimpan dalam satu transaksi
+	err = s.auth.inTransaction(func(tx *AuthService) error {
+		if err := tx.users.UpdatePassword(user.ID, hashedPassword); err != nil {
+			return newInternalError("failed to update password", err)
+		}
+		return tx.issueResetPasswordLink(user)
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := s.auth.RevokeAllSessions(user.ID, ""); err != nil {
+		return err
+	}
+
+	s.auth.recordAudit(meta, AuditAdminUserPasswordReset, user.ID, nil)
+
+	return nil
+}
+
+// DeleteUser - hapus akun user beserta session, token dan counter gagal login miliknya
+func (s *AdminService) DeleteUser(userID string, meta RequestMeta) error {
+	if meta.ActorID == userID {
+		return ErrSelfModification
+	}
+
+	user, err := s.findUserForAdmin(userID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.users.Delete(user.ID, loginAttemptKey(user, "")); err != nil {
+		return newInternalError("failed to delete user", err)
+	}
+
+	s.auth.recordAudit(meta, AuditAdminUserDeleted, user.ID, map[string]interface{}{
+		"email":    user.Email,
+		"username": user.UserName,
+	})
+
+	return nil
+}
+
+// findUserForAdmin - ambil user by ID, ErrUserNotFound jika tidak ada
+func (s *AdminService) findUserForAdmin(userID string) (*models.Users, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, errDatabase(err)
+	}
+	return user, nil
+}
+
+// toAdminUser - mapping model user ke response admin (tanpa data sensitif)
+func toAdminUser(user *models.Users) models.AdminUser {
+	return models.AdminUser{
+		ID:               user.ID,
+		UserName:         user.UserName,
+		Email:            user.Email,
+		NoHandphone:      user.NoHandphone,
+		Role:             user.Role,
+		Locale:           user.Locale,
+		ActiveUser:       user.ActiveUser,
+		EmailVerifiedAt:  user.EmailVerifiedAt,
+		DeactivatedAt:    user.DeactivatedAt,
+		TwoFactorEnabled: user.TwoFactorEnabled,
+		UserBilling:      user.UserBilling,
+		CreatedAt:        user.CreatedAt,
+	}
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"testing"
+)
+
+// adminMeta - request dari admin lain (bukan user yang diubah)
+var adminMeta = RequestMeta{ActorID: "admin-1", IP: "203.0.113.9"}
+
+func TestActivateUserDoesNotVerifyEmail(t *testing.T) {
+	env := newTestEnv(t)
+	admin := NewAdminService(env.auth)
+
+	if _, err := env.auth.Register(registerRequest("alice", "alice@example.com", "081200000001"), RequestMeta{}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	user, err := env.users.FindByEmail("alice@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+
+	_, err = admin.SetUserActive(user.ID, true, adminMeta)
+	assertError(t, err, ErrUnverifiedActivation)
+
+	// Akun yang dinonaktifkan sebelum verifikasi tetap belum aktif setelah diaktifkan ulang
+	if _, err := admin.SetUserActive(user.ID, false, adminMeta); err != nil {
+		t.Fatalf("deactivate: %v", err)
+	}
+	resp, err := admin.SetUserActive(user.ID, true, adminMeta)
+	if err != nil {
+		t.Fatalf("activate: %v", err)
+	}
+	if resp.ActiveUser || resp.EmailVerifiedAt != nil || resp.DeactivatedAt != nil {
+		t.Fatalf("activated user = %+v, want inactive and unverified", resp)
+	}
+
+	stored, err := env.users.FindByID(user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if stored.ActiveUser || stored.EmailVerifiedAt != nil {
+		t.Fatalf("stored user active = %v, verified = %v", stored.ActiveUser, stored.EmailVerifiedAt)
+	}
+}
+
+func TestVerifyUserEmailIsAudited(t *testing.T) {
+	env := newTestEnv(t)
+	admin := NewAdminService(env.auth)
+
+	if _, err := env.auth.Register(registerRequest("alice", "alice@example.com", "081200000001"), RequestMeta{}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	verificationToken := env.lastEmailToken(t, "alice@example.com")
+	user, err := env.users.FindByEmail("alice@example.com")
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+
+	resp, err := admin.VerifyUserEmail(user.ID, adminMeta)
+	if err != nil {
+		t.Fatalf("VerifyUserEmail: %v", err)
+	}
+	if !resp.ActiveUser || resp.EmailVerifiedAt == nil {
+		t.Fatalf("verified user = %+v, want active and verified", resp)
+	}
+
+	_, err = admin.VerifyUserEmail(user.ID, adminMeta)
+	assertError(t, err, ErrEmailAlreadyVerified)
+
+	// Link verifikasi yang belum dipakai dibatalkan
+	resp2, err := env.auth.VerifyEmail(verificationToken, RequestMeta{})
+	if err != nil || !resp2.AlreadyVerified {
+		t.Fatalf("VerifyEmail with old link = %+v, %v", resp2, err)
+	}
+
+	events, total, err := env.repos.Audit.List(models.AuditEventQuery{Action: AuditAdminUserEmailVerified, TargetID: user.ID, Page: 1, PageSize: 10}, nil, nil)
+	if err != nil {
+		t.Fatalf("List audit events: %v", err)
+	}
+	if total != 1 || events[0].ActorID != adminMeta.ActorID {
+		t.Fatalf("audit events = %+v, want one %s by %s", events, AuditAdminUserEmailVerified, adminMeta.ActorID)
+	}
+}
@@ -0,0 +1,128 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/utils"
+	"encoding/json"
+	"log"
+	"time"
+	"unicode/utf8"
+)
+
+// ==================== AUDIT SERVICE ====================
+
+// Action audit event (format: <area>.<objek>.<aksi>)
+const (
+	AuditUserRegistered         = "auth.user.registered"
+	AuditEmailVerified          = "auth.email.verified"
+	AuditLoginSucceeded         = "auth.login.succeeded"
+	AuditLoginFailed            = "auth.login.failed"
+	AuditLogout                 = "auth.logout"
+	AuditAccountUnlocked        = "auth.account.unlocked"
+	AuditPasswordResetRequested = "auth.password.reset_requested"
+	AuditPasswordReset          = "auth.password.reset"
+	AuditPasswordChanged        = "auth.password.changed"
+	AuditTwoFactorEnabled       = "auth.2fa.enabled"
+	AuditTwoFactorDisabled      = "auth.2fa.disabled"
+
+	AuditAdminUserActivated     = "admin.user.activated"
+	AuditAdminUserDeactivated   = "admin.user.deactivated"
+	AuditAdminUserEmailVerified = "admin.user.email_verified"
+	AuditAdminUserRoleChanged   = "admin.user.role_changed"
+	AuditAdminUserPasswordReset = "admin.user.password_reset_forced"
+	AuditAdminUserDeleted       = "admin.user.deleted"
+)
+
+// RequestMeta - metadata request HTTP yang ikut dicatat di audit event
+// ActorID = user yang sedang login (kosong untuk request anonim seperti login / register)
+type RequestMeta struct {
+	ActorID   string
+	IP        string
+	UserAgent string
+	RequestID string
+	Locale    string // bahasa dari header Accept-Language (kosong jika tidak dikirim)
+}
+
+// withActor - salinan meta dengan actor lain (contoh: user yang baru saja login)
+func (m RequestMeta) withActor(actorID string) RequestMeta {
+	m.ActorID = actorID
+	return m
+}
+
+// recordAudit - tulis audit event dengan actor dari meta.ActorID
+// Gagal menulis audit hanya di-log supaya aksi utama (yang sudah terjadi) tidak ikut gagal
+func (s *AuthService) recordAudit(meta RequestMeta, action, targetID string, metadata map[string]interface{}) {
+	event := models.AuditEvent{
+		ActorID:   meta.ActorID,
+		Action:    action,
+		TargetID:  targetID,
+		IPAddress: meta.IP,
+		UserAgent: truncate(meta.UserAgent, 255),
+		RequestID: meta.RequestID,
+	}
+
+	if len(metadata) > 0 {
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			log.Printf("failed to encode audit metadata for %s: %v", action, err)
+		} else {
+			event.Metadata = string(encoded)
+		}
+	}
+
+	if err := s.audit.Create(&event); err != nil {
+		log.Printf("failed to record audit event %s (actor=%s target=%s request=%s): %v", action, meta.ActorID, targetID, meta.RequestID, err)
+	}
+}
+
+// ListAuditEvents - list audit event untuk admin dengan filter actor / target / action / rentang waktu
+func (s *AdminService) ListAuditEvents(query models.AuditEventQuery) (models.AuditEventListResponse, error) {
+	query.Page, query.PageSize = normalizePage(query.Page, query.PageSize)
+
+	validationErr := &utils.ValidationError{}
+	from := parseTimeParam(validationErr, "from", query.From)
+	to := parseTimeParam(validationErr, "to", query.To)
+	if from != nil && to != nil && !from.Before(*to) {
+		validationErr.Add("to", "must be after from")
+	}
+	if len(validationErr.Fields) > 0 {
+		return models.AuditEventListResponse{}, validationErr
+	}
+
+	events, total, err := s.audit.List(query, from, to)
+	if err != nil {
+		return models.AuditEventListResponse{}, errDatabase(err)
+	}
+
+	return models.AuditEventListResponse{
+		Events:   events,
+		Page:     query.Page,
+		PageSize: query.PageSize,
+		Total:    total,
+	}, nil
+}
+
+// parseTimeParam - parse query parameter waktu (RFC3339), error dicatat di validationErr
+func parseTimeParam(validationErr *utils.ValidationError, field, value string) *time.Time {
+	if value == "" {
+		return nil
+	}
+
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		validationErr.Add(field, "must be an RFC3339 timestamp (e.g. 2025-01-31T00:00:00Z)")
+		return nil
+	}
+	return &parsed
+}
+
+// truncate - potong string ke maksimal n byte tanpa memotong karakter UTF-8
+func truncate(value string, n int) string {
+	if len(value) <= n {
+		return value
+	}
+	for n > 0 && !utf8.RuneStart(value[n]) {
+		n--
+	}
+	return value[:n]
+}
@@ -3,15 +3,69 @@ package services
 import (
 	"belajar-go-fiber/models"
 	"belajar-go-fiber/repositories"
+	"belajar-go-fiber/templates"
 	"belajar-go-fiber/utils"
 	"errors"
-	"os"
 	"strings"
+	"time"
 
 	"golang.org/x/crypto/bcrypt"
 	"gorm.io/gorm"
 )
 
+// ==================== AUTH SERVICE ====================
+
+// AuthService - logic register, login, verifikasi email, password, 2FA dan session milik user
+// Repository di-inject lewat NewAuthService: GORM di production, memory untuk unit test tanpa database
+type AuthService struct {
+	users         repositories.UserRepository
+	audit         repositories.AuditRepository
+	outbox        repositories.EmailOutboxRepository
+	sessions      repositories.SessionRepository
+	refreshTokens repositories.RefreshTokenRepository
+	twoFactor     repositories.TwoFactorRepository
+	loginAttempts repositories.LoginAttemptStore
+	tx            repositories.Transactor
+	totpSecrets   *utils.SecretKeyRing // enkripsi secret TOTP di kolom twoFactorSecret
+}
+
+// AuthRepositories - semua repository yang dipakai AuthService
+type AuthRepositories struct {
+	Users         repositories.UserRepository
+	Audit         repositories.AuditRepository
+	Outbox        repositories.EmailOutboxRepository
+	Sessions      repositories.SessionRepository
+	RefreshTokens repositories.RefreshTokenRepository
+	TwoFactor     repositories.TwoFactorRepository
+	LoginAttempts repositories.LoginAttemptStore // counter gagal login (brute-force protection)
+	Tx            repositories.Transactor
+}
+
+// totpKeys - kunci enkripsi secret TOTP (TOTP_ENCRYPTION_KEY)
+func NewAuthService(repos AuthRepositories, totpKeys *utils.SecretKeyRing) *AuthService {
+	return &AuthService{
+		users:         repos.Users,
+		audit:         repos.Audit,
+		outbox:        repos.Outbox,
+		sessions:      repos.Sessions,
+		refreshTokens: repos.RefreshTokens,
+		twoFactor:     repos.TwoFactor,
+		loginAttempts: repos.LoginAttempts,
+		tx:            repos.Tx,
+		totpSecrets:   totpKeys,
+	}
+}
+
+// inTransaction - jalankan fn dengan salinan AuthService yang repository user dan outbox-nya terikat ke satu transaksi
+// Dipakai supaya perubahan data dan email yang memicunya tersimpan bersama (atau tidak sama sekali)
+func (s *AuthService) inTransaction(fn func(tx *AuthService) error) error {
+	return s.tx.Transaction(func(repos repositories.TxRepositories) error {
+		txService := *s
+		txService.users, txService.outbox = repos.Users, repos.Outbox
+		return fn(&txService)
+	})
+}
+
 // ==================== PASSWORD SERVICE ====================
 
 // HashPassword - hash password menggunakan bcrypt
@@ -28,283 +82,553 @@ func CheckPasswordHash(hash, password string) bool {
 
 // ==================== REGISTER SERVICE ====================
 
-// RegisterService - handle logic registrasi user
-func RegisterService(req *models.RegisterRequest) (models.AuthResponse, error) {
+// Register - handle logic registrasi user
+func (s *AuthService) Register(req *models.RegisterRequest, meta RequestMeta) (models.AuthResponse, error) {
 	// Validasi input
 	if err := validateRegisterRequest(req); err != nil {
 		return models.AuthResponse{}, err
 	}
 
 	// Cek email sudah terdaftar
-	exists, err := repositories.IsEmailRegistered(req.Email)
+	exists, err := s.users.IsEmailRegistered(req.Email)
 	if err != nil {
-		return models.AuthResponse{}, errors.New("database error")
+		return models.AuthResponse{}, errDatabase(err)
+	}
+
+	// Username dan nomor HP harus unik supaya bisa dipakai untuk login
+	if err := s.ensureUniqueIdentifiers(req); err != nil {
+		return models.AuthResponse{}, err
 	}
 
 	// Hash password
 	hashedPassword, err := HashPassword(req.Password)
 	if err != nil {
-		return models.AuthResponse{}, errors.New("failed to hash password")
+		return models.AuthResponse{}, newInternalError("failed to hash password", err)
 	}
 
-	// Generate verification token
-	tokenVerificationEmail, err := utils.GenerateVerificationToken(req.Email)
-	if err != nil {
-		return models.AuthResponse{}, errors.New("failed to generate verification token")
+	// Bahasa email: pilihan di body request, fallback ke header Accept-Language
+	locale := req.Locale
+	if locale == "" {
+		locale = meta.Locale
 	}
 
 	// User data yang akan disimpan
+	sentAt := time.Now()
 	user := models.Users{
-		UserName:          req.UserName,
-		Email:             req.Email,
-		NoHandphone:       req.NoHandphone,
-		Password:          hashedPassword,
-		Role:              "user",
-		VerificationToken: tokenVerificationEmail,
+		UserName:           req.UserName,
+		Email:              req.Email,
+		NoHandphone:        req.NoHandphone,
+		Password:           hashedPassword,
+		Role:               "user",
+		Locale:             templates.NormalizeLocale(locale),
+		VerificationSentAt: &sentAt,
 	}
 
 	// Jika email sudah terdaftar
+	var existingUser *models.Users
 	if exists {
 		// Cek apakah user sudah aktif
-		existingUser, err := repositories.FindUserByEmailWithActiveStatus(req.Email)
+		existingUser, err = s.users.FindByEmail(req.Email)
 		if err != nil {
-			return models.AuthResponse{}, errors.New("database error")
+			return models.AuthResponse{}, errDatabase(err)
+		}
+
+		// Akun yang dinonaktifkan admin tidak bisa diambil alih lewat register ulang
+		if existingUser.DeactivatedAt != nil {
+			return models.AuthResponse{}, ErrAccountDisabled
 		}
 
 		// Jika sudah aktif, tidak bisa register ulang
 		if existingUser.ActiveUser {
-			return models.AuthResponse{}, errors.New("email already registered and verified")
+			return models.AuthResponse{}, newError(KindConflict, "email_already_registered", "email already registered and verified")
 		}
+	}
 
-		// Jika belum aktif, update data user yang sebelumnya (re-register)
-		if err := repositories.UpdateInactiveUser(req.Email, &user); err != nil {
-			return models.AuthResponse{}, errors.New("failed to update user")
+	// User, token verifikasi dan email verifikasi disimpan dalam satu transaksi,
+	// email dikirim worker di background (SMTP error tidak menggagalkan registrasi)
+	err = s.inTransaction(func(tx *AuthService) error {
+		if existingUser != nil {
+			// Jika belum aktif, update data user yang sebelumnya (re-register)
+			if err := tx.users.UpdateInactiveUser(req.Email, &user); err != nil {
+				return saveUserError(err, "failed to update user")
+			}
+			user.ID = existingUser.ID
+		} else {
+			// Jika email belum terdaftar, buat user baru
+			if err := tx.users.Create(&user); err != nil {
+				return saveUserError(err, "failed to create user")
+			}
 		}
-	} else {
-		// Jika email belum terdaftar, buat user baru
-		if err := repositories.CreateUser(&user); err != nil {
-			return models.AuthResponse{}, errors.New("failed to create user")
+
+		// Generate verification token (link verifikasi dari register sebelumnya tidak berlaku lagi)
+		verificationToken, err := tx.issueLatestUserToken(user.ID, models.TokenPurposeEmailVerification, emailVerificationTokenTTL)
+		if err != nil {
+			return err
 		}
-	}
 
-	// Kirim email verifikasi
-	if err := sendVerificationEmail(&user); err != nil {
-		return models.AuthResponse{}, errors.New("failed to send verification email")
+		return tx.sendVerificationEmail(&user, verificationToken)
+	})
+	if err != nil {
+		return models.AuthResponse{}, err
 	}
 
+	s.recordAudit(meta.withActor(user.ID), AuditUserRegistered, user.ID, map[string]interface{}{
+		"reRegister": exists,
+	})
+
 	return models.AuthResponse{
 		UserName: req.UserName,
 		Message:  "Registration successful, check your email to verify your account",
 	}, nil
 }
 
-// validateRegisterRequest - validasi request register
+// validateRegisterRequest - validasi aturan bisnis register
+// Format input (required, email, phone, password match) sudah divalidasi di handler lewat tag `validate`
 func validateRegisterRequest(req *models.RegisterRequest) error {
-	if req.UserName == "" || req.Email == "" || req.Password == "" || req.ConfirmPassword == "" || req.NoHandphone == "" {
-		return errors.New("all fields are required")
+	if err := passwordPolicy.Validate(req.Password, req.UserName, req.Email); err != nil {
+		return weakPasswordError(err, "password")
 	}
 
-	if req.Password != req.ConfirmPassword {
-		return errors.New("passwords do not match")
-	}
+	// Simpan nomor HP dalam format standar +62xxxx
+	req.NoHandphone = utils.NormalizePhoneNumber(req.NoHandphone)
 
 	return nil
 }
 
-// sendVerificationEmail - kirim email verifikasi
-func sendVerificationEmail(user *models.Users) error {
-	verifyLink := "https://autovers.site/auth/verify?token=" + user.VerificationToken
+var (
+	ErrUserNameTaken          = newFieldError("username_taken", "userName", "username already taken")
+	ErrPhoneAlreadyRegistered = newFieldError("phone_already_registered", "noHandphone", "phone number already registered")
+)
 
-	htmlBytes, err := os.ReadFile("templates/email/verify-email.html")
+// ensureUniqueIdentifiers - pastikan username dan nomor HP belum dipakai akun lain
+// Hanya untuk pesan error yang jelas, registrasi bersamaan dijaga unique index (lihat saveUserError)
+func (s *AuthService) ensureUniqueIdentifiers(req *models.RegisterRequest) error {
+	taken, err := s.users.IsUserNameTaken(req.UserName, req.Email)
 	if err != nil {
-		return err
+		return errDatabase(err)
+	}
+	if taken {
+		return ErrUserNameTaken
 	}
 
-	emailBody := strings.ReplaceAll(
-		string(htmlBytes),
-		"{{VERIFY_LINK}}",
-		verifyLink,
-	)
+	registered, err := s.users.IsPhoneRegistered(utils.PhoneNumberVariants(req.NoHandphone), req.Email)
+	if err != nil {
+		return errDatabase(err)
+	}
+	if registered {
+		return ErrPhoneAlreadyRegistered
+	}
 
-	return utils.SendMail(
-		user.Email,
-		"Verify your Autovers Account",
-		emailBody,
-	)
+	return nil
 }
 
-// ==================== LOGIN SERVICE ====================
-
-// LoginService - handle logic login user
-func LoginService(req *models.LoginRequest) (models.AuthResponse, *models.Users, error) {
-	// Validasi input
-	if err := validateLoginRequest(req); err != nil {
-		return models.AuthResponse{}, nil, err
+// saveUserError - error dari Create / UpdateInactiveUser, unique violation username / nomor HP jadi error validasi
+func saveUserError(err error, message string) error {
+	switch {
+	case errors.Is(err, repositories.ErrUserNameTaken):
+		return ErrUserNameTaken
+	case errors.Is(err, repositories.ErrPhoneTaken):
+		return ErrPhoneAlreadyRegistered
+	default:
+		return newInternalError(message, err)
 	}
+}
 
-	// Ambil user dari database
-	user, err := repositories.FindUserByEmail(req.Identifier)
+// sendVerificationEmail - masukkan email verifikasi ke outbox
+func (s *AuthService) sendVerificationEmail(user *models.Users, verificationToken string) error {
+	return s.sendTemplateEmail(user, templates.EmailVerifyEmail, templates.EmailData{
+		ActionURL:        emailLink(emailLinks.VerifyPath, verificationToken),
+		ExpiresInMinutes: int(emailVerificationTokenTTL / time.Minute),
+	})
+}
+
+// ==================== RESEND VERIFICATION SERVICE ====================
+
+// resendVerificationCooldown - jarak minimal antar pengiriman email verifikasi ke akun yang sama
+const resendVerificationCooldown = 2 * time.Minute
+
+// ResendVerification - kirim ulang email verifikasi dengan token baru
+// Selalu sukses untuk email yang tidak terdaftar / sudah aktif / masih cooldown
+// supaya tidak membocorkan email mana yang terdaftar
+func (s *AuthService) ResendVerification(email string) error {
+	user, err := s.users.FindByEmail(email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return models.AuthResponse{}, nil, errors.New("invalid credentials")
+			return nil
 		}
-		return models.AuthResponse{}, nil, errors.New("database error")
+		return errDatabase(err)
 	}
 
-	// Cek password
-	if !CheckPasswordHash(user.Password, req.Password) {
-		return models.AuthResponse{}, nil, errors.New("invalid credentials")
+	if user.ActiveUser || user.DeactivatedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	if user.VerificationSentAt != nil && now.Sub(*user.VerificationSentAt) < resendVerificationCooldown {
+		return nil
+	}
+
+	return s.inTransaction(func(tx *AuthService) error {
+		// Link verifikasi yang dikirim sebelumnya tidak berlaku lagi
+		token, err := tx.issueLatestUserToken(user.ID, models.TokenPurposeEmailVerification, emailVerificationTokenTTL)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.users.UpdateVerificationSentAt(user.ID, now); err != nil {
+			return errDatabase(err)
+		}
+
+		// Error SMTP tidak sampai ke response: email dikirim worker di background
+		return tx.sendVerificationEmail(user, token)
+	})
+}
+
+// ==================== LOGIN SERVICE ====================
+
+// Login - handle logic login user
+// ip dipakai untuk brute-force protection per IP (lihat login_guard.service.go)
+func (s *AuthService) Login(req *models.LoginRequest, meta RequestMeta) (models.AuthResponse, *models.Users, error) {
+	// Tolak IP yang terlalu banyak gagal login
+	if err := s.checkIPAllowed(meta.IP); err != nil {
+		return models.AuthResponse{}, nil, s.loginFailed(meta, nil, req.Identifier, err)
+	}
+
+	// Ambil user dari database (identifier bisa email, username, atau nomor HP)
+	user, err := s.findUserByIdentifier(req.Identifier)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return models.AuthResponse{}, nil, errDatabase(err)
+	}
+
+	// Tolak akun yang sedang dikunci / masih dalam progressive delay
+	attemptKey := loginAttemptKey(user, req.Identifier)
+	if err := s.checkAccountAllowed(attemptKey); err != nil {
+		return models.AuthResponse{}, nil, s.loginFailed(meta, user, req.Identifier, err)
+	}
+
+	// Cek user ada dan password benar
+	if user == nil || !CheckPasswordHash(user.Password, req.Password) {
+		err := s.recordLoginFailure(user, attemptKey, meta.IP, ErrInvalidCredentials)
+		return models.AuthResponse{}, nil, s.loginFailed(meta, user, req.Identifier, err)
+	}
+
+	// Akun dinonaktifkan admin
+	if user.DeactivatedAt != nil {
+		return models.AuthResponse{}, nil, s.loginFailed(meta, user, req.Identifier, ErrAccountDisabled)
 	}
 
 	// Cek apakah user sudah verifikasi email
 	if !user.ActiveUser {
-		return models.AuthResponse{}, nil, errors.New("please verify your email first")
+		return models.AuthResponse{}, nil, s.loginFailed(meta, user, req.Identifier, ErrEmailNotVerified)
+	}
+
+	// Jika 2FA aktif, jangan terbitkan auth_token dulu → minta kode 2FA
+	// (counter gagal baru di-reset setelah kode 2FA valid)
+	if user.TwoFactorEnabled {
+		jti, err := s.issueUserToken(user.ID, models.TokenPurposeMFAPending, utils.MFATokenTTL)
+		if err != nil {
+			return models.AuthResponse{}, nil, err
+		}
+
+		mfaToken, err := utils.GenerateMFAToken(user.ID, jti)
+		if err != nil {
+			return models.AuthResponse{}, nil, newInternalError("failed to generate mfa token", err)
+		}
+
+		return models.AuthResponse{
+			UserName:    user.UserName,
+			Message:     "Two-factor authentication required",
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, user, nil
 	}
 
+	s.resetLoginFailures(attemptKey)
+	s.recordAudit(meta.withActor(user.ID), AuditLoginSucceeded, user.ID, map[string]interface{}{
+		"mfa": false,
+	})
+
 	return models.AuthResponse{
 		UserName: user.UserName,
 		Message:  "Login successful",
 	}, user, nil
 }
 
-// validateLoginRequest - validasi request login
-func validateLoginRequest(req *models.LoginRequest) error {
-	if req.Identifier == "" || req.Password == "" {
-		return errors.New("all fields are required")
+// loginFailed - catat audit login gagal lalu kembalikan err apa adanya
+// user boleh nil (identifier tidak terdaftar), reason = code error yang dikirim ke client
+func (s *AuthService) loginFailed(meta RequestMeta, user *models.Users, identifier string, err error) error {
+	metadata := map[string]interface{}{
+		"identifier": identifier,
+		"reason":     errorCode(err),
 	}
-	return nil
+
+	targetID := ""
+	if user != nil {
+		targetID = user.ID
+	}
+
+	s.recordAudit(meta.withActor(targetID), AuditLoginFailed, targetID, metadata)
+	return err
+}
+
+// findUserByIdentifier - cari user berdasarkan email, nomor HP, atau username
+// Email dikenali dari "@", nomor HP dari formatnya (08xx / 628xx / +628xx), sisanya username
+func (s *AuthService) findUserByIdentifier(identifier string) (*models.Users, error) {
+	identifier = strings.TrimSpace(identifier)
+
+	if strings.Contains(identifier, "@") {
+		return s.users.FindByEmail(identifier)
+	}
+
+	if phone := utils.NormalizePhoneNumber(identifier); phone != "" {
+		return s.users.FindByPhone(utils.PhoneNumberVariants(phone))
+	}
+
+	return s.users.FindByUserName(identifier)
+}
+
+// findUser - ambil user yang sedang login by ID, ErrUserNotFound jika tidak ada
+func (s *AuthService) findUser(userID string) (*models.Users, error) {
+	user, err := s.users.FindByID(userID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrUserNotFound
+		}
+		return nil, errDatabase(err)
+	}
+	return user, nil
 }
 
 // ==================== VERIFICATION SERVICE ====================
 
-// VerifyEmailService - handle logic verifikasi email
-func VerifyEmailService(token string) error {
+// VerifyEmail - handle logic verifikasi email
+// Link verifikasi hanya bisa dipakai sekali dan hanya link terakhir yang dikirim yang berlaku.
+// Link milik akun yang sudah aktif menghasilkan response "already verified" (bukan error)
+func (s *AuthService) VerifyEmail(token string, meta RequestMeta) (models.VerifyEmailResponse, error) {
 	if token == "" {
-		return errors.New("verification token is required")
+		return models.VerifyEmailResponse{}, newError(KindBadRequest, "token_required", "verification token is required")
 	}
 
-	claims, err := utils.ParseVerificationToken(token)
+	userToken, err := s.findUserToken(token, models.TokenPurposeEmailVerification, ErrInvalidVerificationToken)
 	if err != nil {
-		return errors.New("invalid or expired verification token")
+		return models.VerifyEmailResponse{}, err
 	}
 
-	// Update user sebagai verified
-	err = repositories.VerifyUserByEmail(claims.Email)
+	user, err := s.users.FindByID(userToken.UserID)
 	if err != nil {
-		return errors.New("failed to verify email")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.VerifyEmailResponse{}, ErrInvalidVerificationToken
+		}
+		return models.VerifyEmailResponse{}, errDatabase(err)
 	}
 
-	return nil
-}
+	if user.DeactivatedAt != nil {
+		return models.VerifyEmailResponse{}, ErrAccountDisabled
+	}
 
-// ==================== FORGOT PASSWORD SERVICE ====================
+	// Link dibuka lagi setelah verifikasi berhasil (atau link lama milik akun yang sudah aktif)
+	if user.ActiveUser {
+		return alreadyVerifiedResponse(), nil
+	}
+
+	// Token sudah dipakai / digantikan link yang lebih baru / expired
+	if !userTokenUsable(userToken) {
+		return models.VerifyEmailResponse{}, ErrInvalidVerificationToken
+	}
+
+	// Pakai token (sekali pakai) dan update user sebagai verified
+	if err := s.users.VerifyEmailWithToken(userToken.ID, user.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Request lain memakai token yang sama lebih dulu (link diklik dua kali)
+			if current, findErr := s.users.FindByID(user.ID); findErr == nil && current.ActiveUser {
+				return alreadyVerifiedResponse(), nil
+			}
+			return models.VerifyEmailResponse{}, ErrInvalidVerificationToken
+		}
+		return models.VerifyEmailResponse{}, newInternalError("failed to verify email", err)
+	}
+
+	s.recordAudit(meta.withActor(user.ID), AuditEmailVerified, user.ID, nil)
+
+	return models.VerifyEmailResponse{
+		Message: "Email verified successfully",
+	}, nil
+}
 
-// ForgotPasswordService - handle logic forgot password
-func ForgotPasswordService(email string) error {
-	// Validasi email
-	if email == "" {
-		return errors.New("email is required")
+// alreadyVerifiedResponse - response untuk link verifikasi milik akun yang sudah aktif
+func alreadyVerifiedResponse() models.VerifyEmailResponse {
+	return models.VerifyEmailResponse{
+		Message:         "Email already verified, you can login",
+		AlreadyVerified: true,
 	}
+}
+
+// ==================== FORGOT PASSWORD SERVICE ====================
 
+// ForgotPassword - handle logic forgot password
+func (s *AuthService) ForgotPassword(email string, meta RequestMeta) error {
 	// Cek email ada di database
-	user, err := repositories.FindUserByEmail(email)
+	user, err := s.users.FindByEmail(email)
 	if err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
 			// Jangan reveal apakah email terdaftar atau tidak (security best practice)
 			return nil
 		}
-		return errors.New("database error")
+		return errDatabase(err)
+	}
+
+	if user.DeactivatedAt != nil {
+		return ErrAccountDisabled
 	}
 
 	// Cek user sudah verified
 	if !user.ActiveUser {
-		return errors.New("please verify your email first")
+		return ErrEmailNotVerified
+	}
+
+	s.recordAudit(meta.withActor(user.ID), AuditPasswordResetRequested, user.ID, nil)
+
+	return s.inTransaction(func(tx *AuthService) error {
+		return tx.issueResetPasswordLink(user)
+	})
+}
+
+// issueResetPasswordLink - generate token reset password lalu masukkan email berisi link-nya ke outbox
+// Panggil di dalam inTransaction supaya token dan email tersimpan bersama
+func (s *AuthService) issueResetPasswordLink(user *models.Users) error {
+	// Hanya link reset password terakhir yang berlaku
+	resetToken, err := s.issueLatestUserToken(user.ID, models.TokenPurposePasswordReset, passwordResetTokenTTL)
+	if err != nil {
+		return err
 	}
 
-	// Generate reset password token dengan Purpose: "password_reset"
-	resetToken, err := utils.GenerateResetPasswordToken(email)
+	return s.sendResetPasswordEmail(user, resetToken)
+}
+
+// ResetPassword - handle logic reset password, semua session user di-revoke
+// Format input (required, password match) sudah divalidasi di handler lewat tag `validate`
+func (s *AuthService) ResetPassword(req *models.ResetPasswordRequest, meta RequestMeta) error {
+	// Token harus ada di database, belum dipakai, dan belum expired
+	userToken, err := s.findUsableUserToken(req.Token, models.TokenPurposePasswordReset, ErrInvalidResetToken)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.users.FindByID(userToken.UserID)
 	if err != nil {
-		return errors.New("failed to generate reset token")
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return errDatabase(err)
 	}
 
-	// Save token ke database (gunakan verificationToken field yang ada)
-	if err := repositories.SaveResetPasswordToken(user.ID, resetToken); err != nil {
-		return errors.New("failed to save reset token")
+	if err := validatePasswordPolicy(req.NewPassword, user); err != nil {
+		return weakPasswordError(err, "newPassword")
 	}
 
-	// Send reset password email
-	if err := sendResetPasswordEmail(user, resetToken); err != nil {
-		return errors.New("failed to send reset password email")
+	// Hash password baru
+	hashedPassword, err := HashPassword(req.NewPassword)
+	if err != nil {
+		return newInternalError("failed to hash password", err)
 	}
 
+	// Pakai token (sekali pakai) dan update password
+	if err := s.users.ResetPasswordWithToken(userToken.ID, user.ID, hashedPassword); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidResetToken
+		}
+		return newInternalError("failed to update password", err)
+	}
+
+	// Reset password = pemulihan akun: session, refresh token dan link unlock yang mungkin sudah dicuri tidak boleh tetap berlaku
+	if err := s.RevokeAllSessions(user.ID, ""); err != nil {
+		return err
+	}
+	if err := s.users.InvalidateTokens(user.ID, models.TokenPurposeAccountUnlock); err != nil {
+		return errDatabase(err)
+	}
+
+	s.recordAudit(meta.withActor(user.ID), AuditPasswordReset, user.ID, nil)
+
 	return nil
 }
 
-// ResetPasswordService - handle logic reset password
-func ResetPasswordService(token, newPassword, confirmPassword string) error {	
-	// Validasi token
-	if token == "" {
-		return errors.New("reset token is required")
-	}
+// sendResetPasswordEmail - masukkan email reset password ke outbox
+func (s *AuthService) sendResetPasswordEmail(user *models.Users, resetToken string) error {
+	return s.sendTemplateEmail(user, templates.EmailResetPassword, templates.EmailData{
+		ActionURL:        emailLink(emailLinks.ResetPasswordPath, resetToken),
+		ExpiresInMinutes: int(passwordResetTokenTTL / time.Minute),
+	})
+}
+
+// ==================== CHANGE PASSWORD SERVICE ====================
+
+var ErrIncorrectPassword = newFieldError("incorrect_password", "currentPassword", "current password is incorrect")
 
-	// Validasi password
-	if newPassword == "" || confirmPassword == "" {
-		return errors.New("all fields are required")
+// ChangePassword - ganti password user yang sedang login (harus tahu password lama)
+// Semua session lain di-revoke, session yang sedang dipakai (sessionID) tetap aktif
+func (s *AuthService) ChangePassword(userID, sessionID string, req *models.ChangePasswordRequest, meta RequestMeta) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
 	}
 
-	if newPassword != confirmPassword {
-		return errors.New("passwords do not match")
+	if !CheckPasswordHash(user.Password, req.CurrentPassword) {
+		return ErrIncorrectPassword
 	}
 
-	// Validasi dan parse token dengan purpose "password_reset"
-	claims, err := utils.ParseResetPasswordToken(token)
-	if err != nil {
-		return errors.New("invalid or expired reset token")
+	if req.NewPassword == req.CurrentPassword {
+		return newFieldError("password_reused", "newPassword", "new password must be different from the current password")
 	}
 
-	// Cari user dari email di token
-	user, err := repositories.FindUserByEmail(claims.Email)
-	if err != nil {
-		return errors.New("user not found")
+	if err := validatePasswordPolicy(req.NewPassword, user); err != nil {
+		return weakPasswordError(err, "newPassword")
 	}
 
-	// Cek token matches di database
-	if user.VerificationToken != token {
-		return errors.New("invalid reset token")
+	hashedPassword, err := HashPassword(req.NewPassword)
+	if err != nil {
+		return newInternalError("failed to hash password", err)
 	}
 
-	// Hash password baru
-	hashedPassword, err := HashPassword(newPassword)
+	// Password baru dan email notifikasi disimpan dalam satu transaksi
+	err = s.inTransaction(func(tx *AuthService) error {
+		if err := tx.users.UpdatePassword(user.ID, hashedPassword); err != nil {
+			return newInternalError("failed to update password", err)
+		}
+		return tx.sendPasswordChangedEmail(user)
+	})
 	if err != nil {
-		return errors.New("failed to hash password")
+		return err
 	}
 
-	// Update password dan clear token
-	if err := repositories.UpdatePasswordAndClearToken(user.ID, hashedPassword); err != nil {
-		return errors.New("failed to update password")
+	// Device lain yang mungkin memakai password lama harus login ulang
+	if err := s.RevokeAllSessions(user.ID, sessionID); err != nil {
+		return err
 	}
 
+	s.recordAudit(meta, AuditPasswordChanged, user.ID, nil)
+
 	return nil
 }
 
-// sendResetPasswordEmail - kirim email reset password
-func sendResetPasswordEmail(user *models.Users, resetToken string) error {
-	resetLink := "https://autovers.site/auth/reset-password?token=" + resetToken
+// ==================== LOCALE SERVICE ====================
 
-	htmlBytes, err := os.ReadFile("templates/email/reset-password.html")
-	if err != nil {
+// UpdateLocale - ganti bahasa yang dipakai untuk email ke user
+func (s *AuthService) UpdateLocale(userID, locale string) error {
+	if _, err := s.findUser(userID); err != nil {
 		return err
 	}
 
-	emailBody := strings.ReplaceAll(
-		string(htmlBytes),
-		"{{RESET_LINK}}",
-		resetLink,
-	)
+	if err := s.users.UpdateLocale(userID, templates.NormalizeLocale(locale)); err != nil {
+		return errDatabase(err)
+	}
+	return nil
+}
 
-	return utils.SendMail(
-		user.Email,
-		"Reset Your Autovers Password",
-		emailBody,
-	)
+// sendPasswordChangedEmail - masukkan notifikasi bahwa password baru saja diganti ke outbox
+func (s *AuthService) sendPasswordChangedEmail(user *models.Users) error {
+	return s.sendTemplateEmail(user, templates.EmailPasswordChanged, templates.EmailData{
+		ActionURL: emailLink(emailLinks.ForgotPasswordPath, ""),
+		ChangedAt: time.Now(),
+	})
 }
@@ -0,0 +1,496 @@
+package services
+
+import (
+	"belajar-go-fiber/config"
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/repositories"
+	"belajar-go-fiber/templates"
+	"belajar-go-fiber/utils"
+	"errors"
+	"log"
+	"os"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// ==================== TEST SETUP ====================
+
+const testPassword = "Str0ngPassw0rd"
+
+func TestMain(m *testing.M) {
+	if err := templates.LoadEmailTemplates(); err != nil {
+		log.Fatalf("failed to load email templates: %v", err)
+	}
+
+	// Key HS256 tanpa kid untuk auth_token dan mfaToken
+	secret := []byte("test-secret-test-secret-test-secret")
+	utils.SetJWTKeys(utils.JWTKey{Algorithm: "HS256", PrivateKey: secret, PublicKey: secret}, nil, secret)
+
+	os.Exit(m.Run())
+}
+
+// testEnv - AuthService dengan semua repository di memory
+type testEnv struct {
+	auth      *AuthService
+	users     *repositories.MemoryUserRepository
+	outbox    *repositories.MemoryEmailOutboxRepository
+	sessions  *repositories.MemorySessionRepository
+	twoFactor *repositories.MemoryTwoFactorRepository
+	attempts  *repositories.MemoryLoginAttemptStore
+	repos     AuthRepositories
+}
+
+func newTestEnv(t *testing.T) *testEnv {
+	t.Helper()
+
+	users := repositories.NewMemoryUserRepository()
+	outbox := repositories.NewMemoryEmailOutboxRepository()
+	refreshTokens := repositories.NewMemoryRefreshTokenRepository()
+	env := &testEnv{
+		users:     users,
+		outbox:    outbox,
+		sessions:  repositories.NewMemorySessionRepository(refreshTokens),
+		twoFactor: repositories.NewMemoryTwoFactorRepository(users),
+		attempts:  repositories.NewMemoryLoginAttemptStore(),
+	}
+	env.repos = AuthRepositories{
+		Users:         users,
+		Audit:         repositories.NewMemoryAuditRepository(),
+		Outbox:        outbox,
+		Sessions:      env.sessions,
+		RefreshTokens: refreshTokens,
+		TwoFactor:     env.twoFactor,
+		LoginAttempts: env.attempts,
+		Tx:            repositories.NewMemoryTransactor(users, outbox),
+	}
+	env.auth = NewAuthService(env.repos, newTOTPKeys(t, testTOTPKey))
+	return env
+}
+
+// testTOTPKey - key TOTP_ENCRYPTION_KEY untuk test
+var testTOTPKey = config.EncryptionKey{ID: "test", Secret: "test-totp-key-test-totp-key-test-totp-key"}
+
+func newTOTPKeys(t *testing.T, keys ...config.EncryptionKey) *utils.SecretKeyRing {
+	t.Helper()
+
+	ring, err := utils.NewSecretKeyRing("autovers-totp-secret", keys)
+	if err != nil {
+		t.Fatalf("NewSecretKeyRing: %v", err)
+	}
+	return ring
+}
+
+func registerRequest(username, email, phone string) *models.RegisterRequest {
+	return &models.RegisterRequest{
+		UserName:        username,
+		Email:           email,
+		NoHandphone:     phone,
+		Password:        testPassword,
+		ConfirmPassword: testPassword,
+	}
+}
+
+var emailTokenPattern = regexp.MustCompile(`token=([A-Za-z0-9_-]+)`)
+
+// lastEmailToken - token dari link di email terakhir ke recipient
+func (env *testEnv) lastEmailToken(t *testing.T, recipient string) string {
+	t.Helper()
+
+	emails := env.outbox.All()
+	for i := len(emails) - 1; i >= 0; i-- {
+		if emails[i].Recipient != recipient {
+			continue
+		}
+		match := emailTokenPattern.FindStringSubmatch(emails[i].TextBody)
+		if match == nil {
+			t.Fatalf("last email to %s has no token link: %q", recipient, emails[i].TextBody)
+		}
+		return match[1]
+	}
+
+	t.Fatalf("no email sent to %s", recipient)
+	return ""
+}
+
+// createVerifiedUser - register lalu verifikasi email lewat link di email verifikasi
+func (env *testEnv) createVerifiedUser(t *testing.T, username, email, phone string) *models.Users {
+	t.Helper()
+
+	if _, err := env.auth.Register(registerRequest(username, email, phone), RequestMeta{}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	if _, err := env.auth.VerifyEmail(env.lastEmailToken(t, email), RequestMeta{}); err != nil {
+		t.Fatalf("VerifyEmail: %v", err)
+	}
+
+	user, err := env.users.FindByEmail(email)
+	if err != nil {
+		t.Fatalf("FindByEmail: %v", err)
+	}
+	return user
+}
+
+func assertError(t *testing.T, err, want error) {
+	t.Helper()
+
+	if !errors.Is(err, want) {
+		t.Fatalf("error = %v, want %v", err, want)
+	}
+}
+
+// ==================== REGISTER ====================
+
+func TestRegisterRejectsDuplicateIdentifiers(t *testing.T) {
+	tests := []struct {
+		name string
+		req  *models.RegisterRequest
+		want error
+	}{
+		{"username", registerRequest("Alice", "other@example.com", "081200000002"), ErrUserNameTaken},
+		{"phone in local format", registerRequest("bob", "other@example.com", "081200000001"), ErrPhoneAlreadyRegistered},
+		{"phone in international format", registerRequest("bob", "other@example.com", "+62 812-0000-0001"), ErrPhoneAlreadyRegistered},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := newTestEnv(t)
+			env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+
+			_, err := env.auth.Register(tt.req, RequestMeta{})
+			assertError(t, err, tt.want)
+
+			if _, err := env.users.FindByEmail("other@example.com"); err == nil {
+				t.Fatal("user with duplicate identifier was created")
+			}
+		})
+	}
+}
+
+func TestRegisterAllowsReRegisterOfUnverifiedEmail(t *testing.T) {
+	env := newTestEnv(t)
+
+	req := registerRequest("alice", "alice@example.com", "081200000001")
+	if _, err := env.auth.Register(req, RequestMeta{}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+
+	// Username dan nomor HP milik akun yang sedang re-register sendiri tidak dihitung bentrok
+	req = registerRequest("alice", "alice@example.com", "081200000001")
+	if _, err := env.auth.Register(req, RequestMeta{}); err != nil {
+		t.Fatalf("re-register: %v", err)
+	}
+}
+
+// ==================== LOGIN ====================
+
+func TestLoginRejectsWrongPassword(t *testing.T) {
+	env := newTestEnv(t)
+	env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+
+	tests := []struct {
+		name       string
+		identifier string
+		password   string
+	}{
+		{"wrong password", "alice@example.com", "Wr0ngPassword"},
+		{"unknown identifier", "nobody@example.com", testPassword},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, user, err := env.auth.Login(&models.LoginRequest{Identifier: tt.identifier, Password: tt.password}, RequestMeta{IP: "203.0.113.1"})
+			assertError(t, err, ErrInvalidCredentials)
+			if user != nil {
+				t.Fatal("user returned for failed login")
+			}
+		})
+	}
+}
+
+func TestLoginDelaysAfterRepeatedFailures(t *testing.T) {
+	env := newTestEnv(t)
+	env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+
+	wrong := &models.LoginRequest{Identifier: "alice", Password: "Wr0ngPassword"}
+	for i := 0; i < loginDelayAfter; i++ {
+		_, _, err := env.auth.Login(wrong, RequestMeta{IP: "203.0.113.1"})
+		assertError(t, err, ErrInvalidCredentials)
+	}
+
+	// Password benar pun harus menunggu progressive delay
+	_, _, err := env.auth.Login(&models.LoginRequest{Identifier: "alice", Password: testPassword}, RequestMeta{IP: "203.0.113.1"})
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Kind != KindRateLimited || appErr.RetryAfter <= 0 {
+		t.Fatalf("error = %v, want too_many_login_attempts", err)
+	}
+}
+
+// lockAccount - buat akun terkunci (gagal login sampai batas), return token dari email unlock
+func (env *testEnv) lockAccount(t *testing.T, user *models.Users) string {
+	t.Helper()
+
+	// Gagal sebelumnya sudah lewat progressive delay, tinggal satu gagal lagi sampai akun dikunci
+	past := time.Now().Add(-2 * time.Minute)
+	for i := 0; i < accountLockThreshold-1; i++ {
+		if _, err := env.attempts.RecordFailure(loginAttemptKey(user, ""), past, loginFailureWindow); err != nil {
+			t.Fatalf("RecordFailure: %v", err)
+		}
+	}
+	emailsBefore := len(env.outbox.All())
+
+	_, _, err := env.auth.Login(&models.LoginRequest{Identifier: user.Email, Password: "Wr0ngPassword"}, RequestMeta{IP: "203.0.113.1"})
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Kind != KindLocked {
+		t.Fatalf("error = %v, want account_locked", err)
+	}
+
+	if got := len(env.outbox.All()); got != emailsBefore+1 {
+		t.Fatalf("emails queued = %d, want unlock email", got-emailsBefore)
+	}
+	return env.lastEmailToken(t, user.Email)
+}
+
+func TestLoginLocksAccountAfterRepeatedFailures(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+	env.lockAccount(t, user)
+
+	// Akun yang dikunci tetap ditolak walaupun password benar
+	_, _, err := env.auth.Login(&models.LoginRequest{Identifier: "alice@example.com", Password: testPassword}, RequestMeta{IP: "203.0.113.1"})
+	var appErr *AppError
+	if !errors.As(err, &appErr) || appErr.Kind != KindLocked {
+		t.Fatalf("error = %v, want account_locked", err)
+	}
+}
+
+// ==================== UNLOCK ACCOUNT ====================
+
+func TestUnlockAccountTokenIsSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+	token := env.lockAccount(t, user)
+
+	if err := env.auth.UnlockAccount(token, RequestMeta{}); err != nil {
+		t.Fatalf("UnlockAccount: %v", err)
+	}
+	if _, _, err := env.auth.Login(&models.LoginRequest{Identifier: "alice", Password: testPassword}, RequestMeta{IP: "203.0.113.1"}); err != nil {
+		t.Fatalf("Login after unlock: %v", err)
+	}
+
+	// Link unlock yang sama tidak bisa dipakai lagi setelah akun dikunci ulang
+	env.lockAccount(t, user)
+	assertError(t, env.auth.UnlockAccount(token, RequestMeta{}), ErrInvalidUnlockToken)
+}
+
+func TestResetPasswordInvalidatesUnlockToken(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+	unlockToken := env.lockAccount(t, user)
+
+	if err := env.auth.ForgotPassword("alice@example.com", RequestMeta{}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	req := &models.ResetPasswordRequest{Token: env.lastEmailToken(t, "alice@example.com"), NewPassword: "N3wPassword", ConfirmPassword: "N3wPassword"}
+	if err := env.auth.ResetPassword(req, RequestMeta{}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	assertError(t, env.auth.UnlockAccount(unlockToken, RequestMeta{}), ErrInvalidUnlockToken)
+}
+
+// ==================== VERIFY EMAIL ====================
+
+func TestVerifyEmailTokenIsSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+
+	if _, err := env.auth.Register(registerRequest("alice", "alice@example.com", "081200000001"), RequestMeta{}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	token := env.lastEmailToken(t, "alice@example.com")
+
+	resp, err := env.auth.VerifyEmail(token, RequestMeta{})
+	if err != nil || resp.AlreadyVerified {
+		t.Fatalf("VerifyEmail = %+v, %v", resp, err)
+	}
+
+	// Link dibuka lagi: tidak memverifikasi ulang, hanya memberi tahu akun sudah aktif
+	resp, err = env.auth.VerifyEmail(token, RequestMeta{})
+	if err != nil || !resp.AlreadyVerified {
+		t.Fatalf("second VerifyEmail = %+v, %v", resp, err)
+	}
+}
+
+func TestVerifyEmailRejectsSupersededToken(t *testing.T) {
+	env := newTestEnv(t)
+
+	req := registerRequest("alice", "alice@example.com", "081200000001")
+	if _, err := env.auth.Register(req, RequestMeta{}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	oldToken := env.lastEmailToken(t, "alice@example.com")
+
+	req = registerRequest("alice", "alice@example.com", "081200000001")
+	if _, err := env.auth.Register(req, RequestMeta{}); err != nil {
+		t.Fatalf("re-register: %v", err)
+	}
+	newToken := env.lastEmailToken(t, "alice@example.com")
+
+	_, err := env.auth.VerifyEmail(oldToken, RequestMeta{})
+	assertError(t, err, ErrInvalidVerificationToken)
+
+	if _, err := env.auth.VerifyEmail(newToken, RequestMeta{}); err != nil {
+		t.Fatalf("VerifyEmail with latest token: %v", err)
+	}
+}
+
+// ==================== RESET PASSWORD ====================
+
+func TestResetPasswordTokenIsSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+
+	if err := env.auth.ForgotPassword("alice@example.com", RequestMeta{}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	token := env.lastEmailToken(t, "alice@example.com")
+
+	req := &models.ResetPasswordRequest{Token: token, NewPassword: "N3wPassword", ConfirmPassword: "N3wPassword"}
+	if err := env.auth.ResetPassword(req, RequestMeta{}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	req = &models.ResetPasswordRequest{Token: token, NewPassword: "An0therPassword", ConfirmPassword: "An0therPassword"}
+	assertError(t, env.auth.ResetPassword(req, RequestMeta{}), ErrInvalidResetToken)
+
+	if _, _, err := env.auth.Login(&models.LoginRequest{Identifier: "alice", Password: "N3wPassword"}, RequestMeta{IP: "203.0.113.1"}); err != nil {
+		t.Fatalf("Login with reset password: %v", err)
+	}
+}
+
+func TestResetPasswordRejectsUnusableTokens(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+
+	if err := env.auth.ForgotPassword("alice@example.com", RequestMeta{}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	superseded := env.lastEmailToken(t, "alice@example.com")
+	if err := env.auth.ForgotPassword("alice@example.com", RequestMeta{}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+
+	expired := "expired-reset-token"
+	err := env.users.CreateToken(&models.UserToken{
+		UserID:    user.ID,
+		Purpose:   models.TokenPurposePasswordReset,
+		TokenHash: utils.HashToken(expired),
+		ExpiresAt: time.Now().Add(-time.Minute),
+	})
+	if err != nil {
+		t.Fatalf("CreateToken: %v", err)
+	}
+
+	// Token verifikasi email tidak bisa dipakai sebagai token reset password
+	if _, err := env.auth.Register(registerRequest("bob", "bob@example.com", "081200000002"), RequestMeta{}); err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	verification := env.lastEmailToken(t, "bob@example.com")
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"superseded", superseded},
+		{"expired", expired},
+		{"other purpose", verification},
+		{"unknown", "unknown-token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &models.ResetPasswordRequest{Token: tt.token, NewPassword: "N3wPassword", ConfirmPassword: "N3wPassword"}
+			assertError(t, env.auth.ResetPassword(req, RequestMeta{}), ErrInvalidResetToken)
+		})
+	}
+}
+
+func TestResetPasswordRevokesAllSessions(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+
+	_, refreshToken, err := env.auth.StartSession(user, "test", "203.0.113.1")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+
+	if err := env.auth.ForgotPassword("alice@example.com", RequestMeta{}); err != nil {
+		t.Fatalf("ForgotPassword: %v", err)
+	}
+	req := &models.ResetPasswordRequest{Token: env.lastEmailToken(t, "alice@example.com"), NewPassword: "N3wPassword", ConfirmPassword: "N3wPassword"}
+	if err := env.auth.ResetPassword(req, RequestMeta{}); err != nil {
+		t.Fatalf("ResetPassword: %v", err)
+	}
+
+	sessions, err := env.sessions.FindActiveByUserID(user.ID)
+	if err != nil {
+		t.Fatalf("FindActiveByUserID: %v", err)
+	}
+	if len(sessions) != 0 {
+		t.Fatalf("active sessions = %d, want 0", len(sessions))
+	}
+
+	if _, _, err := env.auth.RefreshSession(refreshToken); err == nil {
+		t.Fatal("refresh token still valid after password reset")
+	}
+}
+
+// ==================== USER TOKENS ====================
+
+func TestPurgeUserTokensDeletesOnlyStaleTokens(t *testing.T) {
+	env := newTestEnv(t)
+	now := time.Now()
+
+	tokens := []struct {
+		raw       string
+		expiresAt time.Time
+		consumed  bool
+		kept      bool
+	}{
+		{"fresh", now.Add(time.Hour), false, true},
+		{"recently-expired", now.Add(-time.Hour), false, true},
+		{"recently-consumed", now.Add(-time.Hour), true, true},
+		{"stale", now.Add(-userTokenRetention - time.Hour), false, false},
+		{"stale-consumed", now.Add(-userTokenRetention - time.Hour), true, false},
+	}
+
+	for _, tt := range tokens {
+		token := &models.UserToken{
+			UserID:    "user-1",
+			Purpose:   models.TokenPurposePasswordReset,
+			TokenHash: utils.HashToken(tt.raw),
+			ExpiresAt: tt.expiresAt,
+		}
+		if tt.consumed {
+			token.ConsumedAt = &now
+		}
+		if err := env.users.CreateToken(token); err != nil {
+			t.Fatalf("CreateToken: %v", err)
+		}
+	}
+
+	deleted, err := env.auth.PurgeUserTokens(now)
+	if err != nil {
+		t.Fatalf("PurgeUserTokens: %v", err)
+	}
+	if deleted != 2 {
+		t.Fatalf("deleted = %d, want 2", deleted)
+	}
+
+	for _, tt := range tokens {
+		_, err := env.users.FindTokenByHash(models.TokenPurposePasswordReset, utils.HashToken(tt.raw))
+		if kept := err == nil; kept != tt.kept {
+			t.Fatalf("token %s kept = %v, want %v", tt.raw, kept, tt.kept)
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package services
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ==================== BREACHED PASSWORD FILE ====================
+
+// breachedPrefixLength - panjang prefix bucket (sama dengan range API k-anonymity Have I Been Pwned)
+const breachedPrefixLength = 5
+
+// breachedPasswordFile - file hash SHA-1 (hex) password yang pernah bocor, satu hash per baris
+// ("HASH" atau "HASH:COUNT") dan terurut berdasarkan hash, seperti download "ordered by hash" Have I Been Pwned
+//
+// File tidak di-load ke memory: baris pertama bucket prefix dicari dengan binary search
+// di offset file, lalu hanya baris dalam bucket tersebut yang dibaca
+type breachedPasswordFile struct {
+	path string
+	file *os.File // hanya dibaca lewat ReadAt, aman dipakai banyak goroutine
+	size int64
+}
+
+// openBreachedPasswordFile - buka file dan cek baris pertamanya berformat hash SHA-1
+func openBreachedPasswordFile(path string) (*breachedPasswordFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open BREACHED_PASSWORDS_FILE: %w", err)
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read BREACHED_PASSWORDS_FILE: %w", err)
+	}
+
+	f := &breachedPasswordFile{path: path, file: file, size: info.Size()}
+
+	first, err := f.readLine(0)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to read BREACHED_PASSWORDS_FILE: %w", err)
+	}
+	if hash := breachedLineHash(first); hash != "" && !isSHA1Hex(hash) {
+		file.Close()
+		return nil, fmt.Errorf("invalid SHA-1 hash in %s line 1", path)
+	}
+
+	return f, nil
+}
+
+// contains - hash (hex uppercase) ada di file
+func (f *breachedPasswordFile) contains(hash string) (bool, error) {
+	prefix := hash[:breachedPrefixLength]
+
+	// Binary search offset terkecil yang baris berikutnya punya prefix >= prefix yang dicari
+	lo, hi := int64(0), f.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		start, err := f.lineStart(mid)
+		if err != nil {
+			return false, err
+		}
+		if start >= f.size {
+			hi = mid
+			continue
+		}
+
+		line, err := f.readLine(start)
+		if err != nil {
+			return false, err
+		}
+		if breachedLinePrefix(line) >= prefix {
+			hi = mid
+		} else {
+			lo = mid + 1
+		}
+	}
+
+	start, err := f.lineStart(lo)
+	if err != nil {
+		return false, err
+	}
+
+	// Baca bucket prefix baris per baris
+	reader := bufio.NewReader(io.NewSectionReader(f.file, start, f.size-start))
+	for {
+		line, err := reader.ReadString('\n')
+		if line == "" && err != nil {
+			if errors.Is(err, io.EOF) {
+				return false, nil
+			}
+			return false, err
+		}
+
+		if breachedLinePrefix(line) != prefix {
+			return false, nil
+		}
+		if breachedLineHash(line) == hash {
+			return true, nil
+		}
+	}
+}
+
+// lineStart - offset awal baris pertama yang dimulai di offset >= pos
+func (f *breachedPasswordFile) lineStart(pos int64) (int64, error) {
+	if pos == 0 {
+		return 0, nil
+	}
+
+	reader := bufio.NewReader(io.NewSectionReader(f.file, pos-1, f.size-pos+1))
+	skipped, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return 0, err
+	}
+	return pos - 1 + int64(len(skipped)), nil
+}
+
+// readLine - isi baris yang dimulai di offset start (tanpa newline)
+func (f *breachedPasswordFile) readLine(start int64) (string, error) {
+	reader := bufio.NewReader(io.NewSectionReader(f.file, start, f.size-start))
+	line, err := reader.ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// breachedLineHash - hash (uppercase) dari baris "HASH" / "HASH:COUNT"
+func breachedLineHash(line string) string {
+	hash, _, _ := strings.Cut(strings.TrimSpace(line), ":")
+	return strings.ToUpper(hash)
+}
+
+// breachedLinePrefix - prefix bucket dari baris, baris yang lebih pendek dikembalikan utuh
+func breachedLinePrefix(line string) string {
+	hash := breachedLineHash(line)
+	if len(hash) > breachedPrefixLength {
+		return hash[:breachedPrefixLength]
+	}
+	return hash
+}
+
+func isSHA1Hex(hash string) bool {
+	_, err := hex.DecodeString(hash)
+	return err == nil && len(hash) == sha1.Size*2
+}
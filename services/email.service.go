@@ -0,0 +1,41 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/templates"
+	"time"
+)
+
+// ==================== EMAIL SERVICE ====================
+
+// sendTemplateEmail - render template email dalam bahasa pilihan user lalu masukkan ke outbox
+// UserName, BaseURL dan kontak support (lihat SetPublicConfig) diisi otomatis
+func (s *AuthService) sendTemplateEmail(user *models.Users, name string, data templates.EmailData) error {
+	data.UserName = user.UserName
+	data.BaseURL = emailLinks.BaseURL
+	data.SupportEmail = emailLinks.SupportEmail
+	data.SupportURL = emailLinks.SupportURL
+
+	email, err := templates.RenderEmail(name, user.Locale, data)
+	if err != nil {
+		return newInternalError("failed to render email", err)
+	}
+
+	return s.queueEmail(user.Email, email)
+}
+
+// queueEmail - simpan email ke outbox, pengiriman (dan retry) dilakukan EmailWorker di background
+func (s *AuthService) queueEmail(to string, rendered templates.RenderedEmail) error {
+	email := models.EmailOutbox{
+		Recipient:     to,
+		Subject:       rendered.Subject,
+		HTMLBody:      rendered.HTML,
+		TextBody:      rendered.Text,
+		Status:        models.EmailStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	if err := s.outbox.Enqueue(&email); err != nil {
+		return newInternalError("failed to queue email", err)
+	}
+	return nil
+}
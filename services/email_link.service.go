@@ -0,0 +1,33 @@
+package services
+
+import (
+	"belajar-go-fiber/config"
+	"net/url"
+)
+
+// ==================== EMAIL LINK SERVICE ====================
+
+// emailLinks - base URL frontend, path halaman yang dibuka dari link di email dan kontak support
+// Default dipakai sampai SetPublicConfig dipanggil
+var emailLinks = config.PublicConfig{
+	BaseURL:            "https://autovers.site",
+	VerifyPath:         "/auth/verify",
+	ResetPasswordPath:  "/auth/reset-password",
+	ForgotPasswordPath: "/auth/forgot-password",
+	UnlockPath:         "/auth/unlock",
+}
+
+// SetPublicConfig - set URL dan kontak yang dipakai di email (PUBLIC_BASE_URL, EMAIL_*_PATH, SUPPORT_*),
+// sudah divalidasi di config.Load
+func SetPublicConfig(cfg config.PublicConfig) {
+	emailLinks = cfg
+}
+
+// emailLink - URL lengkap ke path di frontend, token (jika ada) dikirim sebagai query ?token=
+func emailLink(path, token string) string {
+	link := emailLinks.BaseURL + path
+	if token != "" {
+		link += "?" + url.Values{"token": {token}}.Encode()
+	}
+	return link
+}
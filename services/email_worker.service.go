@@ -0,0 +1,117 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/repositories"
+	"belajar-go-fiber/utils"
+	"context"
+	"log"
+	"time"
+)
+
+// ==================== EMAIL WORKER ====================
+
+const (
+	emailWorkerInterval  = 5 * time.Second  // jeda polling outbox
+	emailWorkerBatchSize = 20               // maksimal email per polling
+	emailSendLease       = 2 * time.Minute  // email yang sedang dikirim tidak diambil worker lain selama ini
+	emailMaxAttempts     = 8                // setelah gagal sebanyak ini email jadi dead letter
+	emailRetryBaseDelay  = 30 * time.Second // retry: 30s, 1m, 2m, 4m, ... maksimal emailRetryMaxDelay
+	emailRetryMaxDelay   = time.Hour
+)
+
+// EmailWorker - kirim email dari outbox di background dengan retry exponential backoff
+// Aman dijalankan di banyak instance sekaligus (email di-claim dengan SKIP LOCKED)
+type EmailWorker struct {
+	outbox repositories.EmailOutboxRepository
+	mailer utils.Mailer
+}
+
+func NewEmailWorker(outbox repositories.EmailOutboxRepository, mailer utils.Mailer) *EmailWorker {
+	return &EmailWorker{outbox: outbox, mailer: mailer}
+}
+
+// Run - polling outbox sampai ctx selesai
+func (w *EmailWorker) Run(ctx context.Context) {
+	ticker := time.NewTicker(emailWorkerInterval)
+	defer ticker.Stop()
+
+	for {
+		// Kosongkan antrian dulu sebelum menunggu tick berikutnya
+		for {
+			processed, err := w.ProcessDue(time.Now())
+			if err != nil {
+				log.Printf("email worker: failed to claim outbox emails: %v", err)
+			}
+			if err != nil || processed < emailWorkerBatchSize || ctx.Err() != nil {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// ProcessDue - kirim satu batch email yang sudah waktunya dikirim, return jumlah email yang diproses
+func (w *EmailWorker) ProcessDue(now time.Time) (int, error) {
+	emails, err := w.outbox.ClaimDue(now, emailWorkerBatchSize, emailSendLease)
+	if err != nil {
+		return 0, err
+	}
+
+	for i := range emails {
+		w.deliver(&emails[i])
+	}
+	return len(emails), nil
+}
+
+// deliver - kirim satu email lalu catat hasilnya (sent / dijadwalkan ulang / dead letter)
+func (w *EmailWorker) deliver(email *models.EmailOutbox) {
+	err := w.mailer.Send(utils.EmailMessage{
+		To:       email.Recipient,
+		Subject:  email.Subject,
+		HTMLBody: email.HTMLBody,
+		TextBody: email.TextBody,
+	})
+	if err == nil {
+		if err := w.outbox.MarkSent(email.ID, time.Now()); err != nil {
+			log.Printf("email worker: failed to mark email %s as sent: %v", email.ID, err)
+		}
+		return
+	}
+
+	var retryAt *time.Time
+	if email.Attempts < emailMaxAttempts {
+		next := time.Now().Add(emailRetryDelay(email.Attempts))
+		retryAt = &next
+		log.Printf("email worker: failed to send email %s to %s (attempt %d/%d), retrying at %s: %v",
+			email.ID, email.Recipient, email.Attempts, emailMaxAttempts, next.Format(time.RFC3339), err)
+	} else {
+		log.Printf("email worker: giving up on email %s to %s after %d attempts (dead letter): %v",
+			email.ID, email.Recipient, email.Attempts, err)
+	}
+
+	if err := w.outbox.MarkFailed(email.ID, err.Error(), retryAt); err != nil {
+		log.Printf("email worker: failed to record delivery failure for email %s: %v", email.ID, err)
+	}
+}
+
+// emailRetryDelay - exponential backoff berdasarkan jumlah percobaan yang sudah gagal
+func emailRetryDelay(attempts int) time.Duration {
+	if attempts < 1 {
+		attempts = 1
+	}
+	if attempts > 8 {
+		return emailRetryMaxDelay
+	}
+
+	delay := emailRetryBaseDelay << (attempts - 1)
+	if delay > emailRetryMaxDelay {
+		return emailRetryMaxDelay
+	}
+	return delay
+}
@@ -0,0 +1,106 @@
+package services
+
+import (
+	"errors"
+	"time"
+)
+
+// ==================== DOMAIN ERRORS ====================
+
+// ErrorKind - kategori error domain, dipetakan ke HTTP status oleh handlers.ErrorHandler
+type ErrorKind string
+
+const (
+	KindBadRequest   ErrorKind = "bad_request"  // 400 - token invalid / expired, request tidak bisa diproses
+	KindValidation   ErrorKind = "validation"   // 422 - input melanggar aturan (per field jika Details diisi)
+	KindUnauthorized ErrorKind = "unauthorized" // 401 - kredensial / token salah
+	KindForbidden    ErrorKind = "forbidden"    // 403 - tidak boleh melakukan aksi ini
+	KindNotFound     ErrorKind = "not_found"    // 404
+	KindConflict     ErrorKind = "conflict"     // 409 - bentrok dengan data / state yang ada
+	KindLocked       ErrorKind = "locked"       // 423 - akun dikunci sementara
+	KindRateLimited  ErrorKind = "rate_limited" // 429 - terlalu banyak percobaan
+	KindInternal     ErrorKind = "internal"     // 500 - database / bug
+)
+
+// AppError - error domain dengan kode machine-readable yang stabil
+// Client cukup membaca Code, tidak perlu mencocokkan Message
+type AppError struct {
+	Kind       ErrorKind
+	Code       string              // contoh: "email_already_registered"
+	Message    string              // pesan untuk manusia
+	Details    map[string][]string // error per field (nama field JSON), opsional
+	RetryAfter time.Duration       // untuk KindLocked / KindRateLimited, opsional
+	Err        error               // penyebab asli (hanya untuk log, tidak dikirim ke client)
+}
+
+func (e *AppError) Error() string {
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+// Is - dua AppError dianggap sama jika Code-nya sama (errors.Is dengan sentinel)
+func (e *AppError) Is(target error) bool {
+	var other *AppError
+	return errors.As(target, &other) && other.Code == e.Code
+}
+
+func newError(kind ErrorKind, code, message string) *AppError {
+	return &AppError{Kind: kind, Code: code, Message: message}
+}
+
+// newFieldError - error validasi untuk satu field input
+func newFieldError(code, field, message string) *AppError {
+	return &AppError{
+		Kind:    KindValidation,
+		Code:    code,
+		Message: message,
+		Details: map[string][]string{field: {message}},
+	}
+}
+
+// newInternalError - error internal; message tetap dikirim, cause hanya di-log
+func newInternalError(message string, cause error) *AppError {
+	return &AppError{Kind: KindInternal, Code: "internal_error", Message: message, Err: cause}
+}
+
+// errDatabase - error database
+func errDatabase(cause error) *AppError {
+	return newInternalError("database error", cause)
+}
+
+// weakPasswordError - pelanggaran password policy sebagai error validasi pada field password
+func weakPasswordError(err error, field string) error {
+	var policyErr *PasswordPolicyError
+	if !errors.As(err, &policyErr) {
+		return err
+	}
+
+	return &AppError{
+		Kind:    KindValidation,
+		Code:    "weak_password",
+		Message: policyErr.Error(),
+		Details: map[string][]string{field: policyErr.Violations},
+	}
+}
+
+// errorCode - code machine-readable dari error (untuk audit / log)
+func errorCode(err error) string {
+	var appErr *AppError
+	if errors.As(err, &appErr) {
+		return appErr.Code
+	}
+	return "internal_error"
+}
+
+// Error umum yang dipakai di banyak service
+var (
+	ErrUserNotFound             = newError(KindNotFound, "user_not_found", "user not found")
+	ErrEmailNotVerified         = newError(KindForbidden, "email_not_verified", "please verify your email first")
+	ErrAccountDisabled          = newError(KindForbidden, "account_disabled", "account has been disabled, please contact support")
+	ErrInvalidResetToken        = newError(KindBadRequest, "invalid_reset_token", "invalid or expired reset token")
+	ErrInvalidVerificationToken = newError(KindBadRequest, "invalid_verification_token", "invalid or expired verification token")
+	ErrInvalidMFAToken          = newError(KindUnauthorized, "invalid_mfa_token", "invalid or expired mfa token")
+)
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"belajar-go-fiber/config"
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/repositories"
+	"belajar-go-fiber/utils"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+)
+
+// ==================== JWT KEY SERVICE ====================
+
+const (
+	jwtKeySyncInterval = time.Minute // reload key dari database, key baru dari instance lain terbaca dalam 1 menit
+	// jwtKeyPublishDelay - key baru sudah ada di JWKS selama ini sebelum dipakai sign,
+	// supaya semua instance dan service lain (cache JWKS 5 menit) sudah mengenalnya
+	jwtKeyPublishDelay = 10 * time.Minute
+)
+
+// KeyManager - kelola key pair JWT (RS256 / EdDSA) di database:
+// rotasi terjadwal setiap JWT_KEY_ROTATION_INTERVAL, key lama tetap bisa verify selama JWT_KEY_GRACE_PERIOD,
+// private key dienkripsi AES-GCM dengan key turunan SECRET_JWT_AUTOVERS
+// Dengan JWT_ALGORITHM=HS256 token tetap di-sign dengan SECRET_JWT_AUTOVERS (tanpa kid, tidak ada di JWKS)
+type KeyManager struct {
+	keys repositories.SigningKeyRepository
+	cfg  config.JWTConfig
+	box  *utils.SecretBox
+}
+
+func NewKeyManager(keys repositories.SigningKeyRepository, cfg config.JWTConfig) (*KeyManager, error) {
+	return &KeyManager{keys: keys, cfg: cfg, box: utils.NewSecretBox("autovers-jwt-signing-key", cfg.Secret)}, nil
+}
+
+// Run - Sync berkala sampai ctx selesai
+func (m *KeyManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(jwtKeySyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Sync(time.Now()); err != nil {
+				log.Printf("jwt keys: sync failed: %v", err)
+			}
+		}
+	}
+}
+
+// Sync - hapus key expired, rotasi jika sudah waktunya, lalu pasang semua key yang masih berlaku ke utils (sign + verify)
+func (m *KeyManager) Sync(now time.Time) error {
+	if _, err := m.keys.DeleteExpired(now); err != nil {
+		return fmt.Errorf("failed to delete expired jwt keys: %w", err)
+	}
+
+	keys, err := m.keys.ListUsable(now)
+	if err != nil {
+		return fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+
+	if m.cfg.Algorithm != "HS256" && m.rotationDue(keys, now) {
+		if _, err := m.Rotate(now, false); err != nil {
+			return err
+		}
+		if keys, err = m.keys.ListUsable(now); err != nil {
+			return fmt.Errorf("failed to load jwt keys: %w", err)
+		}
+	}
+
+	signing, verify := m.decodeKeys(keys, now)
+
+	// Tidak ada key aktif yang bisa didekripsi (SECRET_JWT_AUTOVERS diganti, contoh: secret acak di development):
+	// key lama tidak bisa dipakai lagi, langsung ganti dengan key baru
+	if signing == nil && m.cfg.Algorithm != "HS256" {
+		log.Printf("jwt keys: no active key can be decrypted, replacing all keys")
+		if _, err := m.rotate(now, now, true); err != nil {
+			return err
+		}
+		if keys, err = m.keys.ListUsable(now); err != nil {
+			return fmt.Errorf("failed to load jwt keys: %w", err)
+		}
+		signing, verify = m.decodeKeys(keys, now)
+	}
+
+	var legacy []byte
+	if m.cfg.Algorithm == "HS256" || m.cfg.LegacyHS256 {
+		legacy = []byte(m.cfg.Secret)
+	}
+
+	if m.cfg.Algorithm == "HS256" {
+		signing = &utils.JWTKey{Algorithm: "HS256", PrivateKey: legacy, PublicKey: legacy}
+	}
+	if signing == nil {
+		return errors.New("no usable jwt signing key")
+	}
+
+	utils.SetJWTKeys(*signing, verify, legacy)
+	return nil
+}
+
+// Rotate - buat key pair baru yang mulai dipakai sign setelah jwtKeyPublishDelay (key pertama langsung aktif);
+// key lama tetap bisa verify sampai key baru aktif + grace period
+// force=false hanya merotasi jika key terbaru sudah lebih tua dari JWT_KEY_ROTATION_INTERVAL
+// Return false jika tidak perlu rotasi (atau instance lain sudah merotasi lebih dulu)
+func (m *KeyManager) Rotate(now time.Time, force bool) (bool, error) {
+	existing, err := m.keys.ListUsable(now)
+	if err != nil {
+		return false, fmt.Errorf("failed to load jwt keys: %w", err)
+	}
+
+	activatesAt := now
+	if len(existing) > 0 {
+		activatesAt = now.Add(jwtKeyPublishDelay)
+	}
+	return m.rotate(now, activatesAt, force)
+}
+
+// rotate - generate, enkripsi dan simpan key pair baru yang aktif pada activatesAt
+func (m *KeyManager) rotate(now, activatesAt time.Time, force bool) (bool, error) {
+	if m.cfg.Algorithm == "HS256" {
+		return false, errors.New("jwt key rotation requires JWT_ALGORITHM=EdDSA or RS256")
+	}
+
+	private, err := utils.GenerateJWTKeyPair(m.cfg.Algorithm)
+	if err != nil {
+		return false, fmt.Errorf("failed to generate jwt key pair: %w", err)
+	}
+	privateDER, publicPEM, err := utils.MarshalJWTKeyPair(private)
+	if err != nil {
+		return false, fmt.Errorf("failed to encode jwt key pair: %w", err)
+	}
+	encrypted, err := m.box.Seal(privateDER)
+	if err != nil {
+		return false, fmt.Errorf("failed to encrypt jwt private key: %w", err)
+	}
+
+	rotateBefore := now.Add(-m.cfg.RotationInterval)
+	if force {
+		// termasuk key pengganti yang belum aktif
+		rotateBefore = now.Add(jwtKeyPublishDelay + time.Second)
+	}
+
+	key := models.SigningKey{
+		Algorithm:   m.cfg.Algorithm,
+		PrivateKey:  encrypted,
+		PublicKey:   publicPEM,
+		ActivatesAt: activatesAt,
+	}
+	rotated, err := m.keys.Rotate(&key, rotateBefore, m.cfg.GracePeriod)
+	if err != nil {
+		return false, fmt.Errorf("failed to store jwt key: %w", err)
+	}
+	if rotated {
+		log.Printf("jwt keys: new %s key %s, used for signing from %s", key.Algorithm, key.ID, key.ActivatesAt.Format(time.RFC3339))
+	}
+	return rotated, nil
+}
+
+// decodeKeys - dekripsi semua key (key yang gagal dilewati), key pertama yang sudah aktif dipakai sign
+// Key pair lama tetap dipakai verify walaupun JWT_ALGORITHM sudah diganti
+func (m *KeyManager) decodeKeys(keys []models.SigningKey, now time.Time) (*utils.JWTKey, []utils.JWTKey) {
+	var signing *utils.JWTKey
+	verify := make([]utils.JWTKey, 0, len(keys))
+
+	for _, stored := range keys {
+		key, err := m.decodeKey(stored)
+		if err != nil {
+			log.Printf("jwt keys: skipping key %s: %v", stored.ID, err)
+			continue
+		}
+		verify = append(verify, key)
+
+		// keys urut ActivatesAt terbaru dulu
+		if signing == nil && !stored.ActivatesAt.After(now) {
+			signing = &key
+		}
+	}
+	return signing, verify
+}
+
+// rotationDue - belum ada key pengganti dan key terbaru sudah tua / algoritmanya bukan JWT_ALGORITHM
+func (m *KeyManager) rotationDue(keys []models.SigningKey, now time.Time) bool {
+	for _, key := range keys {
+		if key.ExpiresAt == nil {
+			return key.Algorithm != m.cfg.Algorithm || key.ActivatesAt.Before(now.Add(-m.cfg.RotationInterval))
+		}
+	}
+	return true
+}
+
+// decodeKey - dekripsi private key dari database
+func (m *KeyManager) decodeKey(stored models.SigningKey) (utils.JWTKey, error) {
+	privateDER, err := m.box.Open(stored.PrivateKey)
+	if err != nil {
+		return utils.JWTKey{}, err
+	}
+
+	private, err := utils.ParseJWTPrivateKey(privateDER)
+	if err != nil {
+		return utils.JWTKey{}, err
+	}
+
+	return utils.JWTKey{
+		ID:         stored.ID,
+		Algorithm:  stored.Algorithm,
+		PrivateKey: private,
+		PublicKey:  private.Public(),
+	}, nil
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/templates"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ==================== LOGIN GUARD SERVICE ====================
+
+// Policy brute-force protection:
+//   - per akun: setelah 3x gagal, percobaan berikutnya harus menunggu 1s, 2s, 4s, ... (maks 60s);
+//     setelah 5x gagal akun dikunci 15 menit dan email unlock dikirim
+//   - per IP: setelah 20x gagal dalam 15 menit, IP ditolak sampai window berakhir
+const (
+	loginFailureWindow   = 15 * time.Minute
+	loginDelayAfter      = 3
+	loginMaxDelay        = 60 * time.Second
+	accountLockThreshold = 5
+	accountLockDuration  = 15 * time.Minute
+	ipFailureThreshold   = 20
+)
+
+var (
+	ErrInvalidCredentials = newError(KindUnauthorized, "invalid_credentials", "invalid credentials")
+	ErrInvalidUnlockToken = newError(KindBadRequest, "invalid_unlock_token", "invalid or expired unlock token")
+)
+
+// throttledError - login ditolak sementara karena terlalu banyak percobaan gagal
+// locked=true → akun dikunci (HTTP 423), locked=false → harus menunggu (HTTP 429)
+func throttledError(retryAfter time.Duration, locked bool) *AppError {
+	if locked {
+		return &AppError{
+			Kind:       KindLocked,
+			Code:       "account_locked",
+			Message:    "account temporarily locked due to too many failed login attempts, check your email to unlock it",
+			RetryAfter: retryAfter,
+		}
+	}
+
+	return &AppError{
+		Kind:       KindRateLimited,
+		Code:       "too_many_login_attempts",
+		Message:    fmt.Sprintf("too many failed login attempts, try again in %d seconds", retryAfterSeconds(retryAfter)),
+		RetryAfter: retryAfter,
+	}
+}
+
+// loginAttemptKey - key counter akun; identifier yang tidak terdaftar tetap dihitung
+// supaya response-nya sama dengan akun yang ada (tidak membocorkan akun mana yang terdaftar)
+func loginAttemptKey(user *models.Users, identifier string) string {
+	if user != nil {
+		return "account:" + user.ID
+	}
+	return "identifier:" + strings.ToLower(strings.TrimSpace(identifier))
+}
+
+// checkIPAllowed - tolak IP yang sudah terlalu banyak gagal dalam window
+func (s *AuthService) checkIPAllowed(ip string) error {
+	attempt, err := s.loginAttempts.Get("ip:" + ip)
+	if err != nil {
+		return errDatabase(err)
+	}
+	if attempt == nil || attempt.Failures < ipFailureThreshold {
+		return nil
+	}
+
+	if wait := time.Until(attempt.LastFailedAt.Add(loginFailureWindow)); wait > 0 {
+		return throttledError(wait, false)
+	}
+	return nil
+}
+
+// checkAccountAllowed - tolak akun yang sedang dikunci atau belum lewat progressive delay
+func (s *AuthService) checkAccountAllowed(key string) error {
+	attempt, err := s.loginAttempts.Get(key)
+	if err != nil {
+		return errDatabase(err)
+	}
+	if attempt == nil {
+		return nil
+	}
+
+	now := time.Now()
+	if attempt.LockedUntil != nil && now.Before(*attempt.LockedUntil) {
+		return throttledError(attempt.LockedUntil.Sub(now), true)
+	}
+
+	if now.Sub(attempt.LastFailedAt) > loginFailureWindow {
+		return nil
+	}
+
+	if wait := attempt.LastFailedAt.Add(loginDelay(attempt.Failures)).Sub(now); wait > 0 {
+		return throttledError(wait, false)
+	}
+	return nil
+}
+
+// recordLoginFailure - catat gagal login untuk akun dan IP, kunci akun jika melewati batas
+// Return error yang harus dikembalikan ke client
+func (s *AuthService) recordLoginFailure(user *models.Users, key, ip string, cause error) error {
+	now := time.Now()
+
+	if _, err := s.loginAttempts.RecordFailure("ip:"+ip, now, loginFailureWindow); err != nil {
+		return errDatabase(err)
+	}
+
+	attempt, err := s.loginAttempts.RecordFailure(key, now, loginFailureWindow)
+	if err != nil {
+		return errDatabase(err)
+	}
+
+	if attempt.Failures < accountLockThreshold {
+		return cause
+	}
+
+	if err := s.loginAttempts.Lock(key, now.Add(accountLockDuration)); err != nil {
+		return errDatabase(err)
+	}
+
+	if user != nil && user.ActiveUser {
+		if err := s.sendUnlockEmail(user); err != nil {
+			log.Printf("failed to send unlock email to %s: %v", user.Email, err)
+		}
+	}
+
+	return throttledError(accountLockDuration, true)
+}
+
+// resetLoginFailures - hapus counter akun setelah login sukses
+func (s *AuthService) resetLoginFailures(key string) {
+	if err := s.loginAttempts.Reset(key); err != nil {
+		log.Printf("failed to reset login attempts for %s: %v", key, err)
+	}
+}
+
+// loginDelay - progressive delay berdasarkan jumlah gagal (1s, 2s, 4s, ... maks loginMaxDelay)
+func loginDelay(failures int) time.Duration {
+	if failures < loginDelayAfter {
+		return 0
+	}
+
+	shift := failures - loginDelayAfter
+	if shift > 6 {
+		return loginMaxDelay
+	}
+
+	delay := time.Second << shift
+	if delay > loginMaxDelay {
+		return loginMaxDelay
+	}
+	return delay
+}
+
+// retryAfterSeconds - durasi tunggu dibulatkan ke atas dalam detik
+func retryAfterSeconds(d time.Duration) int {
+	seconds := int((d + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// ==================== UNLOCK ACCOUNT SERVICE ====================
+
+// UnlockAccount - buka kunci akun dari link di email unlock
+// Token unlock sekali pakai dan dibatalkan oleh reset password
+func (s *AuthService) UnlockAccount(token string, meta RequestMeta) error {
+	if token == "" {
+		return newError(KindBadRequest, "token_required", "unlock token is required")
+	}
+
+	unlockToken, err := s.findUsableUserToken(token, models.TokenPurposeAccountUnlock, ErrInvalidUnlockToken)
+	if err != nil {
+		return err
+	}
+
+	user, err := s.users.FindByID(unlockToken.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidUnlockToken
+		}
+		return errDatabase(err)
+	}
+
+	// Request lain dengan token yang sama sudah lebih dulu memakainya
+	if err := s.users.ConsumeToken(unlockToken.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrInvalidUnlockToken
+		}
+		return errDatabase(err)
+	}
+
+	if err := s.loginAttempts.Reset(loginAttemptKey(user, "")); err != nil {
+		return errDatabase(err)
+	}
+
+	s.recordAudit(meta.withActor(user.ID), AuditAccountUnlocked, user.ID, nil)
+
+	return nil
+}
+
+// sendUnlockEmail - generate token unlock (hanya link terakhir yang berlaku) lalu masukkan email berisi link-nya ke outbox
+func (s *AuthService) sendUnlockEmail(user *models.Users) error {
+	return s.inTransaction(func(tx *AuthService) error {
+		unlockToken, err := tx.issueLatestUserToken(user.ID, models.TokenPurposeAccountUnlock, accountLockDuration)
+		if err != nil {
+			return err
+		}
+
+		return tx.sendTemplateEmail(user, templates.EmailUnlockAccount, templates.EmailData{
+			ActionURL:        emailLink(emailLinks.UnlockPath, unlockToken),
+			ExpiresInMinutes: int(accountLockDuration / time.Minute),
+		})
+	})
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"belajar-go-fiber/config"
+	"belajar-go-fiber/models"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"strings"
+	"unicode"
+)
+
+// ==================== PASSWORD POLICY SERVICE ====================
+
+// bcryptMaxBytes - bcrypt hanya memakai 72 byte pertama, password lebih panjang ditolak
+const bcryptMaxBytes = 72
+
+// PasswordPolicy - aturan password untuk register, reset dan change password
+// Dikonfigurasi via config (lihat LoadPasswordPolicy)
+type PasswordPolicy struct {
+	MinLength     int
+	MaxBytes      int
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+
+	// breached - file hash SHA-1 password yang pernah bocor, nil = tidak dicek
+	breached *breachedPasswordFile
+}
+
+// PasswordPolicyError - daftar aturan password yang dilanggar
+type PasswordPolicyError struct {
+	Violations []string
+}
+
+func (e *PasswordPolicyError) Error() string {
+	return "password does not meet the policy: " + strings.Join(e.Violations, "; ")
+}
+
+// passwordPolicy - policy aktif, default dipakai sampai LoadPasswordPolicy dipanggil
+var passwordPolicy = &PasswordPolicy{
+	MinLength:    8,
+	MaxBytes:     bcryptMaxBytes,
+	RequireUpper: true,
+	RequireLower: true,
+	RequireDigit: true,
+}
+
+// LoadPasswordPolicy - set policy dari config (PASSWORD_* dan BREACHED_PASSWORDS_FILE, lihat config.PasswordConfig)
+// dan load file breached password (jika ada)
+func LoadPasswordPolicy(cfg config.PasswordConfig) error {
+	policy := PasswordPolicy{
+		MinLength:     cfg.MinLength,
+		MaxBytes:      cfg.MaxBytes,
+		RequireUpper:  cfg.RequireUpper,
+		RequireLower:  cfg.RequireLower,
+		RequireDigit:  cfg.RequireDigit,
+		RequireSymbol: cfg.RequireSymbol,
+	}
+	if policy.MaxBytes <= 0 || policy.MaxBytes > bcryptMaxBytes {
+		policy.MaxBytes = bcryptMaxBytes
+	}
+	if policy.MinLength > policy.MaxBytes {
+		return fmt.Errorf("PASSWORD_MIN_LENGTH (%d) must not exceed PASSWORD_MAX_BYTES (%d)", policy.MinLength, policy.MaxBytes)
+	}
+
+	if cfg.BreachedFile != "" {
+		breached, err := openBreachedPasswordFile(cfg.BreachedFile)
+		if err != nil {
+			return err
+		}
+		policy.breached = breached
+	}
+
+	passwordPolicy = &policy
+	return nil
+}
+
+// Validate - cek password terhadap policy; username/email dipakai untuk menolak password yang memuat identitas user
+func (p *PasswordPolicy) Validate(password, username, email string) error {
+	var violations []string
+
+	if len([]rune(password)) < p.MinLength {
+		violations = append(violations, fmt.Sprintf("must be at least %d characters", p.MinLength))
+	}
+	if len(password) > p.MaxBytes {
+		violations = append(violations, fmt.Sprintf("must be at most %d bytes", p.MaxBytes))
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		default:
+			hasSymbol = true
+		}
+	}
+	if p.RequireUpper && !hasUpper {
+		violations = append(violations, "must contain an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		violations = append(violations, "must contain a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		violations = append(violations, "must contain a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		violations = append(violations, "must contain a symbol")
+	}
+
+	lower := strings.ToLower(password)
+	if containsIdentity(lower, username) {
+		violations = append(violations, "must not contain your username")
+	}
+	localPart, _, _ := strings.Cut(email, "@")
+	if containsIdentity(lower, localPart) {
+		violations = append(violations, "must not contain your email address")
+	}
+
+	if p.isBreached(password) {
+		violations = append(violations, "has appeared in a data breach, choose a different password")
+	}
+
+	if len(violations) > 0 {
+		return &PasswordPolicyError{Violations: violations}
+	}
+	return nil
+}
+
+// isBreached - cek hash SHA-1 password di bucket prefix-nya (k-anonymity style, offline)
+// Error baca file hanya di-log, password tidak ditolak karena file bermasalah
+func (p *PasswordPolicy) isBreached(password string) bool {
+	if p.breached == nil {
+		return false
+	}
+
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	found, err := p.breached.contains(hash)
+	if err != nil {
+		log.Printf("failed to check breached password: %v", err)
+		return false
+	}
+	return found
+}
+
+// containsIdentity - password memuat username / email (abaikan identitas yang terlalu pendek)
+func containsIdentity(lowerPassword, identity string) bool {
+	identity = strings.ToLower(strings.TrimSpace(identity))
+	return len(identity) >= 3 && strings.Contains(lowerPassword, identity)
+}
+
+// validatePasswordPolicy - validasi password dengan policy aktif
+func validatePasswordPolicy(password string, user *models.Users) error {
+	return passwordPolicy.Validate(password, user.UserName, user.Email)
+}
@@ -0,0 +1,131 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ==================== REFRESH TOKEN SERVICE ====================
+
+var (
+	ErrInvalidRefreshToken = newError(KindUnauthorized, "invalid_refresh_token", "invalid or expired refresh token")
+	ErrRefreshTokenReused  = newError(KindUnauthorized, "refresh_token_reused", "refresh token reuse detected, please login again")
+)
+
+// IssueRefreshToken - buat refresh token baru (family baru) untuk session
+// Return token asli (untuk cookie), yang disimpan di DB hanya hash-nya
+func (s *AuthService) IssueRefreshToken(userID, sessionID string) (string, error) {
+	familyID, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", newInternalError("failed to generate refresh token", err)
+	}
+
+	rawToken, token, err := newRefreshToken(userID, sessionID, familyID)
+	if err != nil {
+		return "", err
+	}
+
+	if err := s.refreshTokens.Create(token); err != nil {
+		return "", newInternalError("failed to save refresh token", err)
+	}
+
+	return rawToken, nil
+}
+
+// RotateRefreshToken - tukar refresh token lama dengan yang baru
+// Jika token lama sudah pernah dirotasi (replay), seluruh family dan session-nya di-revoke
+// Token yang di-revoke karena session-nya berakhir (logout dsb.) hanya ditolak sebagai token tidak valid
+func (s *AuthService) RotateRefreshToken(rawToken string) (*models.Users, *models.RefreshToken, string, error) {
+	if rawToken == "" {
+		return nil, nil, "", ErrInvalidRefreshToken
+	}
+
+	current, err := s.refreshTokens.FindByHash(utils.HashToken(rawToken))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, "", ErrInvalidRefreshToken
+		}
+		return nil, nil, "", errDatabase(err)
+	}
+
+	if current.RevokedAt != nil {
+		return nil, nil, "", s.rejectRevokedRefreshToken(current)
+	}
+
+	if time.Now().After(current.ExpiresAt) {
+		return nil, nil, "", ErrInvalidRefreshToken
+	}
+
+	user, err := s.users.FindByID(current.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, "", ErrInvalidRefreshToken
+		}
+		return nil, nil, "", errDatabase(err)
+	}
+
+	if !user.ActiveUser {
+		return nil, nil, "", ErrInvalidRefreshToken
+	}
+
+	rawNext, next, err := newRefreshToken(user.ID, current.SessionID, current.FamilyID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	if err := s.refreshTokens.Rotate(current.ID, next); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Token di-revoke request lain di antara FindByHash dan Rotate (rotasi paralel atau logout)
+			revoked, findErr := s.refreshTokens.FindByHash(current.TokenHash)
+			if findErr != nil {
+				return nil, nil, "", errDatabase(findErr)
+			}
+			return nil, nil, "", s.rejectRevokedRefreshToken(revoked)
+		}
+		return nil, nil, "", newInternalError("failed to rotate refresh token", err)
+	}
+
+	return user, next, rawNext, nil
+}
+
+// rejectRevokedRefreshToken - error untuk refresh token yang sudah di-revoke
+// Token hasil rotasi yang dipakai lagi → kemungkinan dicuri, matikan seluruh family dan session-nya
+func (s *AuthService) rejectRevokedRefreshToken(token *models.RefreshToken) error {
+	switch token.RevokedReason {
+	case models.RefreshTokenRevokedRotated, models.RefreshTokenRevokedReuse:
+		if err := s.revokeRefreshTokenFamily(token); err != nil {
+			return errDatabase(err)
+		}
+		return ErrRefreshTokenReused
+	default:
+		return ErrInvalidRefreshToken
+	}
+}
+
+// revokeRefreshTokenFamily - revoke seluruh family refresh token dan session pemiliknya
+func (s *AuthService) revokeRefreshTokenFamily(token *models.RefreshToken) error {
+	if err := s.refreshTokens.RevokeFamily(token.FamilyID); err != nil {
+		return err
+	}
+	return s.sessions.Revoke(token.SessionID)
+}
+
+// newRefreshToken - generate token random dan model-nya (belum disimpan)
+func newRefreshToken(userID, sessionID, familyID string) (string, *models.RefreshToken, error) {
+	rawToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", nil, newInternalError("failed to generate refresh token", err)
+	}
+
+	return rawToken, &models.RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(rawToken),
+		ExpiresAt: time.Now().Add(utils.RefreshTokenTTL),
+	}, nil
+}
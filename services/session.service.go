@@ -0,0 +1,191 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/utils"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ==================== SESSION SERVICE ====================
+
+// lastSeenInterval - lastSeenAt hanya di-update jika sudah lewat interval ini
+// supaya setiap request tidak selalu menulis ke database
+const lastSeenInterval = 1 * time.Minute
+
+var (
+	ErrSessionRevoked  = newError(KindUnauthorized, "session_revoked", "session has been revoked")
+	ErrSessionNotFound = newError(KindNotFound, "session_not_found", "session not found")
+)
+
+// StartSession - buat session baru untuk user yang berhasil login
+// Return auth_token (JWT) dan refresh_token (opaque)
+func (s *AuthService) StartSession(user *models.Users, device, ip string) (string, string, error) {
+	jti, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", "", newInternalError("failed to generate token", err)
+	}
+
+	if len(device) > 255 {
+		device = device[:255]
+	}
+
+	session := models.Session{
+		UserID:     user.ID,
+		JTI:        jti,
+		Device:     device,
+		IPAddress:  ip,
+		LastSeenAt: time.Now(),
+	}
+	if err := s.sessions.Create(&session); err != nil {
+		return "", "", newInternalError("failed to create session", err)
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.UserName, user.Role, session.ID, jti)
+	if err != nil {
+		return "", "", newInternalError("failed to generate token", err)
+	}
+
+	refreshToken, err := s.IssueRefreshToken(user.ID, session.ID)
+	if err != nil {
+		return "", "", err
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+// RefreshSession - rotasi refresh token dan terbitkan auth_token baru untuk session yang sama
+func (s *AuthService) RefreshSession(rawRefreshToken string) (string, string, error) {
+	user, next, rawNext, err := s.RotateRefreshToken(rawRefreshToken)
+	if err != nil {
+		return "", "", err
+	}
+
+	session, err := s.sessions.FindByID(next.SessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return "", "", ErrInvalidRefreshToken
+		}
+		return "", "", errDatabase(err)
+	}
+	if session.RevokedAt != nil {
+		return "", "", ErrSessionRevoked
+	}
+
+	// jti baru → auth_token lama untuk session ini otomatis tidak berlaku
+	jti, err := utils.GenerateRandomToken(16)
+	if err != nil {
+		return "", "", newInternalError("failed to generate token", err)
+	}
+	if err := s.sessions.UpdateJTI(session.ID, jti); err != nil {
+		return "", "", errDatabase(err)
+	}
+
+	accessToken, err := utils.GenerateToken(user.ID, user.Email, user.UserName, user.Role, session.ID, jti)
+	if err != nil {
+		return "", "", newInternalError("failed to generate token", err)
+	}
+
+	return accessToken, rawNext, nil
+}
+
+// ValidateSession - pastikan session dari auth_token masih aktif
+// Dipanggil oleh middleware ProtectRoute di setiap request, ErrSessionRevoked jika session sudah tidak berlaku
+func (s *AuthService) ValidateSession(sessionID, jti string) error {
+	if sessionID == "" || jti == "" {
+		return ErrSessionRevoked
+	}
+
+	session, err := s.sessions.FindByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionRevoked
+		}
+		return errDatabase(err)
+	}
+
+	if session.RevokedAt != nil || session.JTI != jti {
+		return ErrSessionRevoked
+	}
+
+	if time.Since(session.LastSeenAt) > lastSeenInterval {
+		_ = s.sessions.Touch(session.ID)
+	}
+
+	return nil
+}
+
+// EndSession - revoke session (logout)
+func (s *AuthService) EndSession(sessionID string) error {
+	if sessionID == "" {
+		return nil
+	}
+
+	if err := s.sessions.Revoke(sessionID); err != nil {
+		return newInternalError("failed to revoke session", err)
+	}
+	return nil
+}
+
+// Logout - revoke session yang sedang dipakai dan catat audit logout
+func (s *AuthService) Logout(sessionID string, meta RequestMeta) error {
+	if err := s.EndSession(sessionID); err != nil {
+		return err
+	}
+
+	s.recordAudit(meta, AuditLogout, meta.ActorID, map[string]interface{}{
+		"sessionId": sessionID,
+	})
+	return nil
+}
+
+// RevokeAllSessions - paksa sign-out user dari semua device
+// exceptSessionID diisi untuk mempertahankan session yang sedang dipakai
+func (s *AuthService) RevokeAllSessions(userID, exceptSessionID string) error {
+	if err := s.sessions.RevokeUserSessions(userID, exceptSessionID); err != nil {
+		return newInternalError("failed to revoke sessions", err)
+	}
+	return nil
+}
+
+// ListSessions - list session aktif milik user, tandai session yang sedang dipakai
+func (s *AuthService) ListSessions(userID, currentSessionID string) ([]models.SessionInfo, error) {
+	sessions, err := s.sessions.FindActiveByUserID(userID)
+	if err != nil {
+		return nil, errDatabase(err)
+	}
+
+	result := make([]models.SessionInfo, 0, len(sessions))
+	for _, session := range sessions {
+		result = append(result, models.SessionInfo{
+			ID:         session.ID,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			CreatedAt:  session.CreatedAt,
+			LastSeenAt: session.LastSeenAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+
+	return result, nil
+}
+
+// RevokeUserSession - revoke satu session milik user (sign out device tertentu)
+func (s *AuthService) RevokeUserSession(userID, sessionID string) error {
+	session, err := s.sessions.FindByID(sessionID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrSessionNotFound
+		}
+		return errDatabase(err)
+	}
+
+	// Jangan bocorkan keberadaan session milik user lain
+	if session.UserID != userID || session.RevokedAt != nil {
+		return ErrSessionNotFound
+	}
+
+	return s.EndSession(session.ID)
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/utils"
+	"testing"
+)
+
+// startSession - login langsung lewat StartSession, return claims auth_token dan refresh token
+func (env *testEnv) startSession(t *testing.T, user *models.Users) (*utils.JwtClaims, string) {
+	t.Helper()
+
+	accessToken, refreshToken, err := env.auth.StartSession(user, "test", "203.0.113.1")
+	if err != nil {
+		t.Fatalf("StartSession: %v", err)
+	}
+	return parseAccessToken(t, accessToken), refreshToken
+}
+
+func parseAccessToken(t *testing.T, accessToken string) *utils.JwtClaims {
+	t.Helper()
+
+	claims, err := utils.ParseToken(accessToken)
+	if err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+	return claims
+}
+
+func TestRefreshSessionRotatesToken(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+	claims, refreshToken := env.startSession(t, user)
+
+	accessToken, nextRefreshToken, err := env.auth.RefreshSession(refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+	if nextRefreshToken == refreshToken {
+		t.Fatal("refresh token was not rotated")
+	}
+
+	next := parseAccessToken(t, accessToken)
+	if next.SessionID != claims.SessionID {
+		t.Fatalf("session = %s, want %s", next.SessionID, claims.SessionID)
+	}
+
+	// auth_token lama tidak berlaku lagi setelah refresh
+	assertError(t, env.auth.ValidateSession(claims.SessionID, claims.ID), ErrSessionRevoked)
+	if err := env.auth.ValidateSession(next.SessionID, next.ID); err != nil {
+		t.Fatalf("ValidateSession: %v", err)
+	}
+}
+
+func TestRefreshSessionDetectsReuse(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+	_, refreshToken := env.startSession(t, user)
+
+	accessToken, nextRefreshToken, err := env.auth.RefreshSession(refreshToken)
+	if err != nil {
+		t.Fatalf("RefreshSession: %v", err)
+	}
+	next := parseAccessToken(t, accessToken)
+
+	// Refresh token yang sudah dirotasi dipakai lagi → seluruh family dan session di-revoke
+	_, _, err = env.auth.RefreshSession(refreshToken)
+	assertError(t, err, ErrRefreshTokenReused)
+
+	_, _, err = env.auth.RefreshSession(nextRefreshToken)
+	assertError(t, err, ErrRefreshTokenReused)
+	assertError(t, env.auth.ValidateSession(next.SessionID, next.ID), ErrSessionRevoked)
+}
+
+func TestRefreshSessionRejectsUnknownToken(t *testing.T) {
+	env := newTestEnv(t)
+
+	for _, token := range []string{"", "unknown-token"} {
+		_, _, err := env.auth.RefreshSession(token)
+		assertError(t, err, ErrInvalidRefreshToken)
+	}
+}
+
+func TestRefreshSessionAfterLogoutIsNotReuse(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+	claims, refreshToken := env.startSession(t, user)
+	other, _ := env.startSession(t, user)
+
+	if err := env.auth.Logout(claims.SessionID, RequestMeta{ActorID: user.ID}); err != nil {
+		t.Fatalf("Logout: %v", err)
+	}
+
+	// Refresh token dari session yang sudah logout hanya ditolak, bukan dianggap replay
+	_, _, err := env.auth.RefreshSession(refreshToken)
+	assertError(t, err, ErrInvalidRefreshToken)
+
+	if err := env.auth.ValidateSession(other.SessionID, other.ID); err != nil {
+		t.Fatalf("ValidateSession other session: %v", err)
+	}
+}
@@ -0,0 +1,285 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/utils"
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ==================== TWO-FACTOR SERVICE ====================
+
+const (
+	totpIssuer        = "Autovers"
+	recoveryCodeCount = 10
+
+	// totpSecretPrefix - penanda secret TOTP terenkripsi ("enc:<key id>:..."), secret lama (plaintext) tidak punya prefix ini
+	totpSecretPrefix = "enc:"
+)
+
+var ErrInvalidTwoFactorCode = newError(KindUnauthorized, "invalid_two_factor_code", "invalid two-factor code")
+
+// SetupTwoFactor - generate secret TOTP baru (belum aktif sampai dikonfirmasi)
+func (s *AuthService) SetupTwoFactor(userID string) (models.TwoFactorSetupResponse, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return models.TwoFactorSetupResponse{}, err
+	}
+
+	if user.TwoFactorEnabled {
+		return models.TwoFactorSetupResponse{}, newError(KindConflict, "two_factor_already_enabled", "two-factor authentication already enabled")
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		return models.TwoFactorSetupResponse{}, newInternalError("failed to generate secret", err)
+	}
+
+	sealed, err := s.sealTOTPSecret(secret)
+	if err != nil {
+		return models.TwoFactorSetupResponse{}, newInternalError("failed to encrypt secret", err)
+	}
+
+	if err := s.twoFactor.SaveSecret(user.ID, sealed); err != nil {
+		return models.TwoFactorSetupResponse{}, newInternalError("failed to save secret", err)
+	}
+
+	return models.TwoFactorSetupResponse{
+		Secret:     secret,
+		OTPAuthURI: utils.BuildOTPAuthURI(totpIssuer, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor - aktifkan 2FA dengan kode pertama dari authenticator app
+// Return recovery code (plain) yang hanya ditampilkan sekali ke user
+func (s *AuthService) ConfirmTwoFactor(userID, code string, meta RequestMeta) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled {
+		return nil, newError(KindConflict, "two_factor_already_enabled", "two-factor authentication already enabled")
+	}
+	if user.TwoFactorSecret == "" {
+		return nil, newError(KindConflict, "two_factor_not_started", "two-factor setup has not been started")
+	}
+
+	secret, _, err := s.openTOTPSecret(user.TwoFactorSecret)
+	if err != nil {
+		return nil, newInternalError("failed to read two-factor secret", err)
+	}
+
+	step, ok := utils.ValidateTOTPCode(secret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidTwoFactorCode
+	}
+
+	plainCodes, codes, err := generateRecoveryCodes(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.twoFactor.Enable(user.ID, step, codes); err != nil {
+		return nil, newInternalError("failed to enable two-factor authentication", err)
+	}
+
+	s.recordAudit(meta, AuditTwoFactorEnabled, user.ID, nil)
+
+	return plainCodes, nil
+}
+
+// DisableTwoFactor - nonaktifkan 2FA (butuh password dan kode 2FA)
+func (s *AuthService) DisableTwoFactor(userID, password, code string, meta RequestMeta) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+
+	if !user.TwoFactorEnabled {
+		return newError(KindConflict, "two_factor_not_enabled", "two-factor authentication is not enabled")
+	}
+
+	if !CheckPasswordHash(user.Password, password) {
+		return newFieldError("incorrect_password", "password", "password is incorrect")
+	}
+
+	if err := s.verifyTwoFactorCode(user, code); err != nil {
+		return err
+	}
+
+	if err := s.twoFactor.Disable(user.ID); err != nil {
+		return newInternalError("failed to disable two-factor authentication", err)
+	}
+
+	s.recordAudit(meta, AuditTwoFactorDisabled, user.ID, nil)
+
+	return nil
+}
+
+// CompleteTwoFactorLogin - langkah kedua login: tukar mfaToken + kode 2FA dengan user
+// Kode 2FA yang salah dihitung sebagai gagal login (brute-force protection)
+// mfaToken sekali pakai: jti-nya di-consume setelah kode 2FA valid
+func (s *AuthService) CompleteTwoFactorLogin(req *models.TwoFactorLoginRequest, meta RequestMeta) (models.AuthResponse, *models.Users, error) {
+	claims, err := utils.ParseMFAToken(req.MFAToken)
+	if err != nil {
+		return models.AuthResponse{}, nil, ErrInvalidMFAToken
+	}
+
+	mfaToken, err := s.findUsableUserToken(claims.ID, models.TokenPurposeMFAPending, ErrInvalidMFAToken)
+	if err != nil {
+		return models.AuthResponse{}, nil, err
+	}
+	if mfaToken.UserID != claims.Subject {
+		return models.AuthResponse{}, nil, ErrInvalidMFAToken
+	}
+
+	user, err := s.users.FindByID(claims.Subject)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.AuthResponse{}, nil, ErrInvalidMFAToken
+		}
+		return models.AuthResponse{}, nil, errDatabase(err)
+	}
+
+	if !user.ActiveUser || !user.TwoFactorEnabled {
+		return models.AuthResponse{}, nil, ErrInvalidMFAToken
+	}
+
+	attemptKey := loginAttemptKey(user, "")
+	if err := s.checkAccountAllowed(attemptKey); err != nil {
+		return models.AuthResponse{}, nil, s.loginFailed(meta, user, user.Email, err)
+	}
+
+	if err := s.verifyTwoFactorCode(user, req.Code); err != nil {
+		if errors.Is(err, ErrInvalidTwoFactorCode) {
+			err = s.recordLoginFailure(user, attemptKey, meta.IP, err)
+			return models.AuthResponse{}, nil, s.loginFailed(meta, user, user.Email, err)
+		}
+		return models.AuthResponse{}, nil, err
+	}
+
+	// Request lain dengan mfaToken yang sama sudah lebih dulu berhasil
+	if err := s.users.ConsumeToken(mfaToken.ID); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return models.AuthResponse{}, nil, ErrInvalidMFAToken
+		}
+		return models.AuthResponse{}, nil, errDatabase(err)
+	}
+
+	s.resetLoginFailures(attemptKey)
+	s.recordAudit(meta.withActor(user.ID), AuditLoginSucceeded, user.ID, map[string]interface{}{
+		"mfa": true,
+	})
+
+	return models.AuthResponse{
+		UserName: user.UserName,
+		Message:  "Login successful",
+	}, user, nil
+}
+
+// verifyTwoFactorCode - cek kode TOTP (sekali pakai per time-step) atau recovery code
+// Secret lama (plaintext atau key TOTP lama) dienkripsi ulang dengan key aktif setelah kode valid
+func (s *AuthService) verifyTwoFactorCode(user *models.Users, code string) error {
+	code = strings.TrimSpace(code)
+
+	secret, current, err := s.openTOTPSecret(user.TwoFactorSecret)
+	if err != nil {
+		return newInternalError("failed to read two-factor secret", err)
+	}
+
+	if step, ok := utils.ValidateTOTPCode(secret, code, time.Now()); ok {
+		fresh, err := s.twoFactor.UseStep(user.ID, step)
+		if err != nil {
+			return errDatabase(err)
+		}
+		if !fresh {
+			return ErrInvalidTwoFactorCode
+		}
+	} else {
+		used, err := s.twoFactor.ConsumeRecoveryCode(user.ID, utils.HashToken(normalizeRecoveryCode(code)))
+		if err != nil {
+			return errDatabase(err)
+		}
+		if !used {
+			return ErrInvalidTwoFactorCode
+		}
+	}
+
+	if !current {
+		s.upgradeTOTPSecret(user.ID, secret)
+	}
+
+	return nil
+}
+
+// sealTOTPSecret - enkripsi secret TOTP sebelum disimpan di database
+func (s *AuthService) sealTOTPSecret(secret string) (string, error) {
+	sealed, err := s.totpSecrets.Seal([]byte(secret))
+	if err != nil {
+		return "", err
+	}
+	return totpSecretPrefix + sealed, nil
+}
+
+// openTOTPSecret - kebalikan sealTOTPSecret, secret lama tanpa prefix dikembalikan apa adanya
+// current = false jika secret belum dienkripsi dengan key TOTP aktif
+func (s *AuthService) openTOTPSecret(stored string) (string, bool, error) {
+	sealed, ok := strings.CutPrefix(stored, totpSecretPrefix)
+	if !ok {
+		return stored, false, nil
+	}
+
+	secret, current, err := s.totpSecrets.Open(sealed)
+	if err != nil {
+		return "", false, err
+	}
+	return string(secret), current, nil
+}
+
+// upgradeTOTPSecret - enkripsi ulang secret TOTP lama dengan key aktif, gagal hanya di-log karena login tetap valid
+func (s *AuthService) upgradeTOTPSecret(userID, secret string) {
+	sealed, err := s.sealTOTPSecret(secret)
+	if err == nil {
+		err = s.twoFactor.UpdateSecret(userID, sealed)
+	}
+	if err != nil {
+		log.Printf("failed to encrypt two-factor secret of user %s: %v", userID, err)
+	}
+}
+
+// generateRecoveryCodes - generate recovery code format "xxxxx-xxxxx"
+func generateRecoveryCodes(userID string) ([]string, []models.RecoveryCode, error) {
+	encoding := base32.StdEncoding.WithPadding(base32.NoPadding)
+
+	plainCodes := make([]string, 0, recoveryCodeCount)
+	codes := make([]models.RecoveryCode, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, newInternalError("failed to generate recovery codes", err)
+		}
+
+		raw := strings.ToLower(encoding.EncodeToString(b))[:10]
+		plain := raw[:5] + "-" + raw[5:]
+
+		plainCodes = append(plainCodes, plain)
+		codes = append(codes, models.RecoveryCode{
+			UserID:   userID,
+			CodeHash: utils.HashToken(normalizeRecoveryCode(plain)),
+		})
+	}
+
+	return plainCodes, codes, nil
+}
+
+// normalizeRecoveryCode - abaikan huruf besar/kecil dan tanda "-" saat user mengetik recovery code
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
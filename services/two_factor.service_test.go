@@ -0,0 +1,152 @@
+package services
+
+import (
+	"belajar-go-fiber/config"
+	"belajar-go-fiber/models"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// totpCode - kode TOTP (RFC 6238, SHA-1, 6 digit, 30 detik) seperti authenticator app
+func totpCode(t *testing.T, secret string, at time.Time) string {
+	t.Helper()
+
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(secret)
+	if err != nil {
+		t.Fatalf("invalid TOTP secret: %v", err)
+	}
+
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(at.Unix()/30))
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%06d", value%1000000)
+}
+
+// enableTwoFactor - setup dan konfirmasi 2FA, return secret (plain) dan recovery code
+func (env *testEnv) enableTwoFactor(t *testing.T, user *models.Users) (string, []string) {
+	t.Helper()
+
+	setup, err := env.auth.SetupTwoFactor(user.ID)
+	if err != nil {
+		t.Fatalf("SetupTwoFactor: %v", err)
+	}
+
+	codes, err := env.auth.ConfirmTwoFactor(user.ID, totpCode(t, setup.Secret, time.Now()), RequestMeta{})
+	if err != nil {
+		t.Fatalf("ConfirmTwoFactor: %v", err)
+	}
+	return setup.Secret, codes
+}
+
+// startTwoFactorLogin - login dengan password benar, return mfaToken
+func (env *testEnv) startTwoFactorLogin(t *testing.T, identifier string) string {
+	t.Helper()
+
+	resp, _, err := env.auth.Login(&models.LoginRequest{Identifier: identifier, Password: testPassword}, RequestMeta{IP: "203.0.113.1"})
+	if err != nil {
+		t.Fatalf("Login: %v", err)
+	}
+	if !resp.MFARequired || resp.MFAToken == "" {
+		t.Fatalf("Login = %+v, want mfaToken", resp)
+	}
+	return resp.MFAToken
+}
+
+func TestTwoFactorSecretIsStoredEncrypted(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+
+	secret, _ := env.enableTwoFactor(t, user)
+
+	stored, err := env.users.FindByID(user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !strings.HasPrefix(stored.TwoFactorSecret, totpSecretPrefix) || strings.Contains(stored.TwoFactorSecret, secret) {
+		t.Fatalf("stored secret %q is not encrypted", stored.TwoFactorSecret)
+	}
+}
+
+func TestTwoFactorLoginTokenIsSingleUse(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+	_, codes := env.enableTwoFactor(t, user)
+
+	mfaToken := env.startTwoFactorLogin(t, "alice")
+
+	// Kode salah tidak memakai mfaToken
+	_, _, err := env.auth.CompleteTwoFactorLogin(&models.TwoFactorLoginRequest{MFAToken: mfaToken, Code: "000000"}, RequestMeta{IP: "203.0.113.1"})
+	assertError(t, err, ErrInvalidTwoFactorCode)
+
+	if _, _, err := env.auth.CompleteTwoFactorLogin(&models.TwoFactorLoginRequest{MFAToken: mfaToken, Code: codes[0]}, RequestMeta{IP: "203.0.113.1"}); err != nil {
+		t.Fatalf("CompleteTwoFactorLogin: %v", err)
+	}
+
+	_, _, err = env.auth.CompleteTwoFactorLogin(&models.TwoFactorLoginRequest{MFAToken: mfaToken, Code: codes[1]}, RequestMeta{IP: "203.0.113.1"})
+	assertError(t, err, ErrInvalidMFAToken)
+}
+
+func TestTwoFactorLoginEncryptsLegacySecret(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+	secret, codes := env.enableTwoFactor(t, user)
+
+	// Secret yang disimpan sebelum enkripsi (plaintext) tetap bisa dipakai
+	if err := env.twoFactor.UpdateSecret(user.ID, secret); err != nil {
+		t.Fatalf("UpdateSecret: %v", err)
+	}
+
+	mfaToken := env.startTwoFactorLogin(t, "alice")
+	if _, _, err := env.auth.CompleteTwoFactorLogin(&models.TwoFactorLoginRequest{MFAToken: mfaToken, Code: codes[0]}, RequestMeta{IP: "203.0.113.1"}); err != nil {
+		t.Fatalf("CompleteTwoFactorLogin: %v", err)
+	}
+
+	stored, err := env.users.FindByID(user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !strings.HasPrefix(stored.TwoFactorSecret, totpSecretPrefix) {
+		t.Fatalf("legacy secret was not encrypted after login: %q", stored.TwoFactorSecret)
+	}
+}
+
+func TestTwoFactorLoginReEncryptsSecretWithActiveKey(t *testing.T) {
+	env := newTestEnv(t)
+	user := env.createVerifiedUser(t, "alice", "alice@example.com", "081200000001")
+	_, codes := env.enableTwoFactor(t, user)
+
+	// Key baru di depan, key lama tetap ada untuk membaca secret yang sudah tersimpan
+	newKey := config.EncryptionKey{ID: "next", Secret: "next-totp-key-next-totp-key-next-totp-key"}
+	env.auth = NewAuthService(env.repos, newTOTPKeys(t, newKey, testTOTPKey))
+
+	mfaToken := env.startTwoFactorLogin(t, "alice")
+	if _, _, err := env.auth.CompleteTwoFactorLogin(&models.TwoFactorLoginRequest{MFAToken: mfaToken, Code: codes[0]}, RequestMeta{IP: "203.0.113.1"}); err != nil {
+		t.Fatalf("CompleteTwoFactorLogin: %v", err)
+	}
+
+	stored, err := env.users.FindByID(user.ID)
+	if err != nil {
+		t.Fatalf("FindByID: %v", err)
+	}
+	if !strings.HasPrefix(stored.TwoFactorSecret, totpSecretPrefix+newKey.ID+":") {
+		t.Fatalf("secret was not re-encrypted with the active key: %q", stored.TwoFactorSecret)
+	}
+
+	// Secret tetap terbaca setelah key lama dihapus
+	env.auth = NewAuthService(env.repos, newTOTPKeys(t, newKey))
+	mfaToken = env.startTwoFactorLogin(t, "alice")
+	if _, _, err := env.auth.CompleteTwoFactorLogin(&models.TwoFactorLoginRequest{MFAToken: mfaToken, Code: codes[1]}, RequestMeta{IP: "203.0.113.1"}); err != nil {
+		t.Fatalf("CompleteTwoFactorLogin without old key: %v", err)
+	}
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"belajar-go-fiber/models"
+	"belajar-go-fiber/utils"
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ==================== USER TOKEN SERVICE ====================
+
+// Umur token yang dikirim lewat email
+const (
+	emailVerificationTokenTTL = 15 * time.Minute
+	passwordResetTokenTTL     = 30 * time.Minute
+)
+
+const (
+	userTokenPurgeInterval = time.Hour
+	// userTokenRetention - token expired / sudah dipakai masih disimpan selama ini sebelum dihapus,
+	// supaya link verifikasi yang dibuka lagi masih dijawab "sudah terverifikasi"
+	userTokenRetention = 7 * 24 * time.Hour
+)
+
+// issueUserToken - buat token sekali pakai untuk user, return token asli (untuk link email)
+// Yang disimpan di database hanya hash-nya
+func (s *AuthService) issueUserToken(userID, purpose string, ttl time.Duration) (string, error) {
+	raw, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return "", newInternalError("failed to generate token", err)
+	}
+
+	token := models.UserToken{
+		UserID:    userID,
+		Purpose:   purpose,
+		TokenHash: utils.HashToken(raw),
+		ExpiresAt: time.Now().Add(ttl),
+	}
+	if err := s.users.CreateToken(&token); err != nil {
+		return "", newInternalError("failed to save token", err)
+	}
+
+	return raw, nil
+}
+
+// findUserToken - cari token berdasarkan token asli, termasuk yang sudah dipakai / expired
+// invalidErr dikembalikan untuk token kosong / tidak dikenal
+func (s *AuthService) findUserToken(raw, purpose string, invalidErr *AppError) (*models.UserToken, error) {
+	if raw == "" {
+		return nil, invalidErr
+	}
+
+	token, err := s.users.FindTokenByHash(purpose, utils.HashToken(raw))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, invalidErr
+		}
+		return nil, errDatabase(err)
+	}
+
+	return token, nil
+}
+
+// findUsableUserToken - cari token yang belum dipakai dan belum expired
+// invalidErr dikembalikan untuk token kosong / tidak dikenal / sudah dipakai / expired
+func (s *AuthService) findUsableUserToken(raw, purpose string, invalidErr *AppError) (*models.UserToken, error) {
+	token, err := s.findUserToken(raw, purpose, invalidErr)
+	if err != nil {
+		return nil, err
+	}
+
+	if !userTokenUsable(token) {
+		return nil, invalidErr
+	}
+
+	return token, nil
+}
+
+// userTokenUsable - token belum dipakai / dibatalkan dan belum expired
+func userTokenUsable(token *models.UserToken) bool {
+	return token.ConsumedAt == nil && time.Now().Before(token.ExpiresAt)
+}
+
+// issueLatestUserToken - batalkan token lama dengan purpose yang sama lalu buat token baru
+// Dipakai untuk link email yang hanya boleh berlaku untuk yang terakhir dikirim
+func (s *AuthService) issueLatestUserToken(userID, purpose string, ttl time.Duration) (string, error) {
+	if err := s.users.InvalidateTokens(userID, purpose); err != nil {
+		return "", errDatabase(err)
+	}
+
+	return s.issueUserToken(userID, purpose, ttl)
+}
+
+// RunUserTokenPurge - PurgeUserTokens berkala sampai ctx selesai
+// Aman dijalankan di banyak instance sekaligus (DELETE idempotent)
+func (s *AuthService) RunUserTokenPurge(ctx context.Context) {
+	ticker := time.NewTicker(userTokenPurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		if deleted, err := s.PurgeUserTokens(time.Now()); err != nil {
+			log.Printf("user tokens: purge failed: %v", err)
+		} else if deleted > 0 {
+			log.Printf("user tokens: purged %d stale tokens", deleted)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PurgeUserTokens - hapus token yang expired lebih dari userTokenRetention yang lalu, return jumlah yang dihapus
+func (s *AuthService) PurgeUserTokens(now time.Time) (int64, error) {
+	return s.users.DeleteStaleTokens(now.Add(-userTokenRetention))
+}
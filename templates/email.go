@@ -0,0 +1,190 @@
+package templates
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"slices"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+)
+
+// Template email ada di templates/email dan ikut di-embed ke binary:
+//   email/layout.html, email/layout.txt    kerangka email (HTML dan plain text)
+//   email/<locale>/common.{html,txt}       sapaan dan footer
+//   email/<locale>/<nama>.html             block "title" dan "content"
+//   email/<locale>/<nama>.txt              block "subject" dan "body"
+// Semua template di-parse sekali lalu di-cache
+
+//go:embed email
+var emailFiles embed.FS
+
+// Nama template email
+const (
+	EmailVerifyEmail     = "verify-email"
+	EmailResetPassword   = "reset-password"
+	EmailPasswordChanged = "password-changed"
+	EmailUnlockAccount   = "unlock-account"
+)
+
+var emailNames = []string{EmailVerifyEmail, EmailResetPassword, EmailPasswordChanged, EmailUnlockAccount}
+
+// DefaultLocale - bahasa email jika user belum memilih / memilih bahasa yang tidak didukung
+const DefaultLocale = "en"
+
+// SupportedLocales - bahasa yang punya template lengkap
+var SupportedLocales = []string{"en", "id"}
+
+// EmailData - data yang bisa dipakai di template email
+type EmailData struct {
+	Locale           string    // diisi RenderEmail
+	Year             int       // diisi RenderEmail jika kosong (footer copyright)
+	UserName         string    // kosong → sapaan tanpa nama
+	ActionURL        string    // link tombol utama (verifikasi, reset password, unlock, ...)
+	BaseURL          string    // URL publik frontend (PUBLIC_BASE_URL), link ke website di footer
+	ExpiresInMinutes int       // umur link di ActionURL
+	ChangedAt        time.Time // waktu perubahan (password changed)
+	SupportEmail     string    // opsional, tampil di footer
+	SupportURL       string    // opsional, tampil di footer
+}
+
+// RenderedEmail - hasil render: subject, body HTML dan alternatif plain text
+type RenderedEmail struct {
+	Subject string
+	HTML    string
+	Text    string
+}
+
+type emailTemplate struct {
+	html *htmltemplate.Template
+	text *texttemplate.Template
+}
+
+var (
+	loadOnce       sync.Once
+	emailTemplates map[string]emailTemplate // key: <locale>/<nama>
+	loadErr        error
+)
+
+// LoadEmailTemplates - parse semua template email (sekali saja), panggil saat startup supaya template rusak langsung ketahuan
+func LoadEmailTemplates() error {
+	loadOnce.Do(func() {
+		emailTemplates, loadErr = parseEmailTemplates()
+	})
+	return loadErr
+}
+
+// RenderEmail - render template email dalam bahasa locale (fallback ke DefaultLocale)
+func RenderEmail(name, locale string, data EmailData) (RenderedEmail, error) {
+	if err := LoadEmailTemplates(); err != nil {
+		return RenderedEmail{}, err
+	}
+
+	data.Locale = NormalizeLocale(locale)
+	if data.Year == 0 {
+		data.Year = time.Now().Year()
+	}
+
+	tmpl, ok := emailTemplates[data.Locale+"/"+name]
+	if !ok {
+		return RenderedEmail{}, fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tmpl.text.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("email %s/%s subject: %w", data.Locale, name, err)
+	}
+	if err := tmpl.text.Execute(&text, data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("email %s/%s text: %w", data.Locale, name, err)
+	}
+	if err := tmpl.html.Execute(&html, data); err != nil {
+		return RenderedEmail{}, fmt.Errorf("email %s/%s html: %w", data.Locale, name, err)
+	}
+
+	return RenderedEmail{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
+}
+
+// NormalizeLocale - "id-ID" / "ID" → "id", bahasa yang tidak didukung → DefaultLocale
+func NormalizeLocale(locale string) string {
+	locale = strings.ToLower(strings.TrimSpace(locale))
+	if i := strings.IndexAny(locale, "-_"); i >= 0 {
+		locale = locale[:i]
+	}
+
+	if IsSupportedLocale(locale) {
+		return locale
+	}
+	return DefaultLocale
+}
+
+// IsSupportedLocale - cek kode bahasa persis ada di SupportedLocales
+func IsSupportedLocale(locale string) bool {
+	return slices.Contains(SupportedLocales, locale)
+}
+
+func parseEmailTemplates() (map[string]emailTemplate, error) {
+	funcs := map[string]interface{}{
+		"datetime": formatDateTime,
+	}
+
+	result := make(map[string]emailTemplate)
+	for _, locale := range SupportedLocales {
+		for _, name := range emailNames {
+			dir := "email/" + locale + "/"
+
+			html, err := htmltemplate.New("layout.html").Funcs(funcs).
+				ParseFS(emailFiles, "email/layout.html", dir+"common.html", dir+name+".html")
+			if err != nil {
+				return nil, fmt.Errorf("email %s/%s: %w", locale, name, err)
+			}
+
+			text, err := texttemplate.New("layout.txt").Funcs(funcs).
+				ParseFS(emailFiles, "email/layout.txt", dir+"common.txt", dir+name+".txt")
+			if err != nil {
+				return nil, fmt.Errorf("email %s/%s: %w", locale, name, err)
+			}
+
+			for _, block := range []string{"title", "content", "greeting", "footer"} {
+				if html.Lookup(block) == nil {
+					return nil, fmt.Errorf("email %s/%s: html template must define %q", locale, name, block)
+				}
+			}
+			for _, block := range []string{"subject", "body", "greeting", "footer"} {
+				if text.Lookup(block) == nil {
+					return nil, fmt.Errorf("email %s/%s: text template must define %q", locale, name, block)
+				}
+			}
+
+			result[locale+"/"+name] = emailTemplate{html: html, text: text}
+		}
+	}
+	return result, nil
+}
+
+// indonesianMonths - nama bulan untuk format tanggal bahasa Indonesia
+var indonesianMonths = [...]string{
+	"Januari", "Februari", "Maret", "April", "Mei", "Juni",
+	"Juli", "Agustus", "September", "Oktober", "November", "Desember",
+}
+
+// formatDateTime - format waktu di zona Asia/Jakarta sesuai bahasa, contoh "02 Jan 2006 15:04 WIB" / "02 Januari 2006 15:04 WIB"
+func formatDateTime(t time.Time, locale string) string {
+	loc, err := time.LoadLocation("Asia/Jakarta")
+	if err != nil {
+		// Fallback ke UTC jika timezone tidak tersedia
+		loc = time.UTC
+	}
+	t = t.In(loc)
+
+	if locale == "id" {
+		return fmt.Sprintf("%02d %s %d %s", t.Day(), indonesianMonths[t.Month()-1], t.Year(), t.Format("15:04 MST"))
+	}
+	return t.Format("02 Jan 2006 15:04 MST")
+}
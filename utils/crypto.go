@@ -0,0 +1,118 @@
+package utils
+
+import (
+	"belajar-go-fiber/config"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strings"
+)
+
+// GenerateRandomToken - generate token random (URL-safe) dengan panjang n byte
+func GenerateRandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken - hash SHA-256 (hex) untuk token yang disimpan di database
+// Token asli tidak pernah disimpan, jadi kebocoran database tidak membocorkan token
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// SecretBox - enkripsi AES-256-GCM untuk data rahasia yang disimpan di database (private key JWT, secret TOTP)
+// Key AES = SHA-256(purpose + ":" + secret), purpose membedakan pemakaian secret yang sama
+type SecretBox struct {
+	aead cipher.AEAD
+}
+
+func NewSecretBox(purpose, secret string) *SecretBox {
+	key := sha256.Sum256([]byte(purpose + ":" + secret))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(err) // key selalu 32 byte
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(err)
+	}
+	return &SecretBox{aead: aead}
+}
+
+// Seal - enkripsi plaintext, hasil base64(nonce || ciphertext)
+func (b *SecretBox) Seal(plaintext []byte) (string, error) {
+	nonce := make([]byte, b.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	return base64.StdEncoding.EncodeToString(b.aead.Seal(nonce, nonce, plaintext, nil)), nil
+}
+
+// Open - kebalikan Seal, error jika data rusak atau dienkripsi dengan secret lain
+func (b *SecretBox) Open(sealed string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(sealed)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < b.aead.NonceSize() {
+		return nil, errors.New("encrypted data is too short")
+	}
+
+	nonce, ciphertext := data[:b.aead.NonceSize()], data[b.aead.NonceSize():]
+	plaintext, err := b.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, errors.New("cannot decrypt data")
+	}
+	return plaintext, nil
+}
+
+// SecretKeyRing - beberapa SecretBox dengan key id, supaya key enkripsi bisa diganti tanpa kehilangan data lama
+// Key pertama dipakai untuk Seal, semua key bisa dipakai untuk Open (dipilih dari id di depan data)
+type SecretKeyRing struct {
+	activeID string
+	boxes    map[string]*SecretBox
+}
+
+func NewSecretKeyRing(purpose string, keys []config.EncryptionKey) (*SecretKeyRing, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("at least one encryption key is required")
+	}
+
+	ring := &SecretKeyRing{activeID: keys[0].ID, boxes: make(map[string]*SecretBox, len(keys))}
+	for _, key := range keys {
+		ring.boxes[key.ID] = NewSecretBox(purpose, key.Secret)
+	}
+	return ring, nil
+}
+
+// Seal - enkripsi dengan key aktif, hasil "id:" + SecretBox.Seal
+func (r *SecretKeyRing) Seal(plaintext []byte) (string, error) {
+	sealed, err := r.boxes[r.activeID].Seal(plaintext)
+	if err != nil {
+		return "", err
+	}
+	return r.activeID + ":" + sealed, nil
+}
+
+// Open - kebalikan Seal, current = false jika data dienkripsi dengan key lama (sebaiknya di-Seal ulang)
+func (r *SecretKeyRing) Open(sealed string) (plaintext []byte, current bool, err error) {
+	id, data, ok := strings.Cut(sealed, ":")
+	box, found := r.boxes[id]
+	if !ok || !found {
+		return nil, false, errors.New("encrypted data uses an unknown key")
+	}
+
+	plaintext, err = box.Open(data)
+	if err != nil {
+		return nil, false, err
+	}
+	return plaintext, id == r.activeID, nil
+}
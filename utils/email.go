@@ -1,31 +1,158 @@
 package utils
 
 import (
-	"strconv"
+	"belajar-go-fiber/config"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
 	"gopkg.in/gomail.v2"
-
-	"os"
 )
 
-func SendMail(	to string, subject string, body string) error {
-	port, _ := strconv.Atoi(os.Getenv("SMTP_PORT"))
+// EmailMessage - satu email yang akan dikirim, TextBody (opsional) = alternatif plain text dari HTMLBody
+type EmailMessage struct {
+	To       string
+	Subject  string
+	HTMLBody string
+	TextBody string
+}
+
+// Mailer - transport pengiriman email
+// Implementasi: SMTPMailer (production), FileMailer (development, tulis ke folder / log) dan MemoryMailer (unit test)
+type Mailer interface {
+	Send(msg EmailMessage) error
+}
 
+// NewMailer - pilih transport sesuai MAIL_TRANSPORT ("smtp", "file", atau "memory"), lihat config.MailConfig
+func NewMailer(cfg config.MailConfig) (Mailer, error) {
+	switch cfg.Transport {
+	case "", "smtp":
+		return NewSMTPMailer(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPass, cfg.From), nil
+	case "file":
+		return &FileMailer{Dir: cfg.FileDir, From: cfg.From}, nil
+	case "memory":
+		return &MemoryMailer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail transport %q (supported: smtp, file, memory)", cfg.Transport)
+	}
+}
 
-	mailer := gomail.NewMessage()
-	mailer.SetHeader("From", os.Getenv("SMTP_FROM"))
-	mailer.SetHeader("To", to)
-	mailer.SetHeader("Subject", subject)
-	mailer.SetBody("text/html", body)
+// ==================== SMTP MAILER ====================
 
-	dialer := gomail.NewDialer(
-		"smtp.hostinger.com",
-		port,
-		os.Getenv("SMTP_USER"),
-		os.Getenv("SMTP_PASS"),
+// SMTPMailer - kirim email lewat server SMTP
+type SMTPMailer struct {
+	dialer *gomail.Dialer
+	from   string
+}
 
+func NewSMTPMailer(host string, port int, username, password, from string) *SMTPMailer {
+	return &SMTPMailer{
+		dialer: gomail.NewDialer(host, port, username, password),
+		from:   from,
+	}
+}
+
+func (m *SMTPMailer) Send(msg EmailMessage) error {
+	mail := gomail.NewMessage()
+	mail.SetHeader("From", m.from)
+	mail.SetHeader("To", msg.To)
+	mail.SetHeader("Subject", msg.Subject)
+	if msg.TextBody != "" {
+		// multipart/alternative: client yang tidak bisa menampilkan HTML memakai versi plain text
+		mail.SetBody("text/plain", msg.TextBody)
+		mail.AddAlternative("text/html", msg.HTMLBody)
+	} else {
+		mail.SetBody("text/html", msg.HTMLBody)
+	}
+
+	return m.dialer.DialAndSend(mail)
+}
+
+// ==================== FILE MAILER ====================
+
+// FileMailer - tidak mengirim email, tapi menulis setiap email ke file .eml di Dir (bisa dibuka di email client)
+// Jika Dir kosong, email hanya ditulis ke log. Untuk development / staging
+type FileMailer struct {
+	Dir  string
+	From string
+}
+
+func (m *FileMailer) Send(msg EmailMessage) error {
+	if m.Dir == "" {
+		body := msg.TextBody
+		if body == "" {
+			body = msg.HTMLBody
+		}
+		log.Printf("[mail] to=%s subject=%q\n%s", msg.To, msg.Subject, body)
+		return nil
+	}
+
+	headers := fmt.Sprintf(
+		"From: %s\r\nTo: %s\r\nSubject: %s\r\nDate: %s\r\nMIME-Version: 1.0\r\n",
+		m.From, msg.To, msg.Subject, time.Now().Format(time.RFC1123Z),
 	)
 
-	return dialer.DialAndSend(mailer)
+	var content string
+	if msg.TextBody != "" {
+		boundary := fmt.Sprintf("alt-%d", time.Now().UnixNano())
+		content = headers + fmt.Sprintf(
+			"Content-Type: multipart/alternative; boundary=%q\r\n\r\n"+
+				"--%s\r\nContent-Type: text/plain; charset=UTF-8\r\n\r\n%s\r\n"+
+				"--%s\r\nContent-Type: text/html; charset=UTF-8\r\n\r\n%s\r\n"+
+				"--%s--\r\n",
+			boundary, boundary, msg.TextBody, boundary, msg.HTMLBody, boundary,
+		)
+	} else {
+		content = headers + "Content-Type: text/html; charset=UTF-8\r\n\r\n" + msg.HTMLBody
+	}
+
+	if err := os.MkdirAll(m.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102-150405.000000000"), safeFileName(msg.To))
+	return os.WriteFile(filepath.Join(m.Dir, name), []byte(content), 0o644)
+}
+
+// safeFileName - ganti karakter selain huruf, angka, "." dan "-" dengan "_"
+func safeFileName(value string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '.' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') {
+			return r
+		}
+		return '_'
+	}, value)
+}
+
+// ==================== MEMORY MAILER ====================
+
+// MemoryMailer - simpan email terkirim di memory, untuk unit test
+// Isi Err untuk mensimulasikan SMTP yang gagal
+type MemoryMailer struct {
+	mu   sync.Mutex
+	sent []EmailMessage
+	Err  error
 }
 
+func (m *MemoryMailer) Send(msg EmailMessage) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.Err != nil {
+		return m.Err
+	}
+	m.sent = append(m.sent, msg)
+	return nil
+}
+
+// Sent - salinan semua email yang berhasil "dikirim"
+func (m *MemoryMailer) Sent() []EmailMessage {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	return append([]EmailMessage(nil), m.sent...)
+}
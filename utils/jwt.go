@@ -1,26 +1,35 @@
 package utils
 
 import (
-	"os"
+	"fmt"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 )
 
-var JwtSecret = []byte(getJwtSecret())
+const (
+	AccessTokenTTL  = 1 * time.Hour       // umur auth_token (JWT)
+	RefreshTokenTTL = 30 * 24 * time.Hour // umur refresh_token (opaque, disimpan di DB)
+	MFATokenTTL     = 5 * time.Minute     // umur mfaToken (login 2FA)
+)
 
-func getJwtSecret() string {
-	secret := os.Getenv("SECRET_JWT_AUTOVERS")
-	if secret == "" {
-		secret = "SECRET_JWT_AUTOVERS" // fallback
-	}
-	return secret
-}
+// Issuer, audience dan header typ JWT
+// Semua jenis token di-sign dengan key yang sama (dipublikasikan di JWKS), jadi verifier
+// wajib cek ketiganya supaya mfaToken tidak bisa dipakai sebagai auth_token
+const (
+	JWTIssuer           = "autovers-auth"
+	AccessTokenAudience = "autovers-api"
+	AccessTokenType     = "at+jwt" // RFC 9068
+
+	mfaTokenAudience = "autovers-auth-mfa"
+	mfaTokenType     = "mfa+jwt"
+)
 
 type JwtClaims struct {
-	Email    string `json:"email"`
-	UserName string `json:"username"`
-	Role     string `json:"role"`
+	Email     string `json:"email"`
+	UserName  string `json:"username"`
+	Role      string `json:"role"`
+	SessionID string `json:"sid"`
 	jwt.RegisteredClaims
 }
 
@@ -31,7 +40,8 @@ type VerificationClaims struct {
 }
 
 // Generate JWT token
-func GenerateToken(email, username, role string) (string, error) {
+// userID disimpan di "sub", jti harus sama dengan jti di session (lihat models.Session)
+func GenerateToken(userID, email, username, role, sessionID, jti string) (string, error) {
 	loc, err := time.LoadLocation("Asia/Jakarta")
 	if err != nil {
 		// Fallback ke UTC jika timezone tidak tersedia
@@ -39,82 +49,68 @@ func GenerateToken(email, username, role string) (string, error) {
 	}
 	
 	claims := &JwtClaims{
-		Email:    email,
-		UserName: username,
-		Role:     role,
+		Email:     email,
+		UserName:  username,
+		Role:      role,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().In(loc).Add(time.Hour * 1)), // 1 jam
+			Issuer:    JWTIssuer,
+			Audience:  jwt.ClaimStrings{AccessTokenAudience},
+			Subject:   userID,
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().In(loc).Add(AccessTokenTTL)), // 1 jam
 			IssuedAt:  jwt.NewNumericDate(time.Now().In(loc)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JwtSecret)
+	return signJWT(claims, AccessTokenType)
 }
 
 // Parse & verify token
+// Hanya menerima access token (typ at+jwt, iss dan aud sesuai), mfaToken ditolak
 func ParseToken(tokenString string) (*JwtClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &JwtClaims{}, func(t *jwt.Token) (interface{}, error) {
-		return JwtSecret, nil
-	})
-	if err != nil {
+	claims := &JwtClaims{}
+	if err := parseJWT(tokenString, claims, AccessTokenType, AccessTokenAudience); err != nil {
 		return nil, err
 	}
 
-	if claims, ok := token.Claims.(*JwtClaims); ok && token.Valid {
-		return claims, nil
-	}
-
-	return nil, err
+	return claims, nil
 }
 
-func GenerateVerificationToken(email string) (string, error) {
-	loc, err := time.LoadLocation("Asia/Jakarta")
+// parseJWT - verify signature dan exp, lalu cek header typ, iss dan aud
+func parseJWT(tokenString string, claims jwt.Claims, typ, audience string) error {
+	token, err := jwt.ParseWithClaims(tokenString, claims, jwtKeyFunc)
 	if err != nil {
-		// Fallback ke UTC jika timezone tidak tersedia
-		loc = time.UTC
+		return err
 	}
-
-	claims := &VerificationClaims{
-		Email:   email,
-		Purpose: "email_verification",
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().In(loc).Add(15 * time.Minute)),
-			IssuedAt:  jwt.NewNumericDate(time.Now().In(loc)),
-		},
+	if !token.Valid {
+		return jwt.ErrTokenInvalidClaims
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JwtSecret)
-}
-
-func ParseVerificationToken(tokenString string) (*VerificationClaims, error) {
-	token, err := jwt.ParseWithClaims(
-		tokenString,
-		&VerificationClaims{},
-		func(t *jwt.Token) (interface{}, error) {
-			return JwtSecret, nil
-		},
-	)
-
-	if err != nil {
-		return nil, err
+	if typ == AccessTokenType && isLegacyAccessToken(token) {
+		return nil
 	}
 
-	claims, ok := token.Claims.(*VerificationClaims)
-	if !ok || !token.Valid {
-		return nil, jwt.ErrTokenInvalidClaims
+	if header, _ := token.Header["typ"].(string); header != typ {
+		return fmt.Errorf("%w: unexpected token type %q", jwt.ErrTokenInvalidClaims, header)
 	}
 
-	if claims.Purpose != "email_verification" {
-		return nil, jwt.ErrTokenInvalidClaims
-	}
+	return jwt.NewValidator(jwt.WithIssuer(JWTIssuer), jwt.WithAudience(audience)).Validate(claims)
+}
 
-	return claims, nil
+// isLegacyAccessToken - auth_token HS256 tanpa kid yang diterbitkan sebelum ada typ / iss / aud
+// (hanya lolos jika JWT_ACCEPT_LEGACY_HS256 aktif, secret HS256 tidak pernah dipublikasikan di JWKS)
+// Tetap harus cocok dengan session (sid + jti) di ProtectRoute, mfaToken lama tidak punya session
+func isLegacyAccessToken(token *jwt.Token) bool {
+	kid, _ := token.Header["kid"].(string)
+	header, _ := token.Header["typ"].(string)
+	return kid == "" && (header == "" || header == "JWT")
 }
 
-// Generate Reset Password Token
-func GenerateResetPasswordToken(email string) (string, error) {
+// Generate MFA Pending Token
+// Diterbitkan setelah password benar untuk akun dengan 2FA, ditukar dengan auth_token di /auth/login/2fa
+// jti sekali pakai, hash-nya disimpan di user_tokens (purpose mfa_pending)
+func GenerateMFAToken(userID, jti string) (string, error) {
 	loc, err := time.LoadLocation("Asia/Jakarta")
 	if err != nil {
 		// Fallback ke UTC jika timezone tidak tersedia
@@ -122,38 +118,28 @@ func GenerateResetPasswordToken(email string) (string, error) {
 	}
 
 	claims := &VerificationClaims{
-		Email:   email,
-		Purpose: "password_reset", // Purpose untuk membedakan dari email_verification
+		Purpose: "mfa_pending",
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().In(loc).Add(30 * time.Minute)), // 30 menit
+			Issuer:    JWTIssuer,
+			Audience:  jwt.ClaimStrings{mfaTokenAudience},
+			Subject:   userID,
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().In(loc).Add(MFATokenTTL)), // 5 menit
 			IssuedAt:  jwt.NewNumericDate(time.Now().In(loc)),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(JwtSecret)
+	return signJWT(claims, mfaTokenType)
 }
 
-// Parse Reset Password Token
-func ParseResetPasswordToken(tokenString string) (*VerificationClaims, error) {
-	token, err := jwt.ParseWithClaims(
-		tokenString,
-		&VerificationClaims{},
-		func(t *jwt.Token) (interface{}, error) {
-			return JwtSecret, nil
-		},
-	)
-
-	if err != nil {
+// Parse MFA Pending Token
+func ParseMFAToken(tokenString string) (*VerificationClaims, error) {
+	claims := &VerificationClaims{}
+	if err := parseJWT(tokenString, claims, mfaTokenType, mfaTokenAudience); err != nil {
 		return nil, err
 	}
 
-	claims, ok := token.Claims.(*VerificationClaims)
-	if !ok || !token.Valid {
-		return nil, jwt.ErrTokenInvalidClaims
-	}
-
-	if claims.Purpose != "password_reset" {
+	if claims.Purpose != "mfa_pending" || claims.Subject == "" || claims.ID == "" {
 		return nil, jwt.ErrTokenInvalidClaims
 	}
 
@@ -0,0 +1,199 @@
+package utils
+
+import (
+	"belajar-go-fiber/models"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"sync/atomic"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ==================== JWT KEYS ====================
+
+// JWTKey - satu key untuk sign / verify JWT
+//
+//	EdDSA: PrivateKey ed25519.PrivateKey, PublicKey ed25519.PublicKey
+//	RS256: PrivateKey *rsa.PrivateKey,    PublicKey *rsa.PublicKey
+//	HS256: PrivateKey dan PublicKey []byte (secret yang sama)
+type JWTKey struct {
+	ID         string // "kid" di header token, kosong untuk HS256
+	Algorithm  string
+	PrivateKey crypto.PrivateKey // nil jika key hanya untuk verify
+	PublicKey  crypto.PublicKey
+}
+
+type jwtKeySet struct {
+	signing *JWTKey
+	byKid   map[string]*JWTKey
+	ordered []*JWTKey // key ber-kid, key signing dulu (urutan JWKS)
+	legacy  []byte    // secret HS256 untuk token tanpa kid, nil = ditolak
+}
+
+// jwtKeys - key aktif, diganti atomik oleh SetJWTKeys (lihat services.KeyManager)
+var jwtKeys atomic.Pointer[jwtKeySet]
+
+// SetJWTKeys - set key untuk sign dan daftar key untuk verify (berdasarkan kid)
+// legacySecret (opsional) dipakai untuk verify token HS256 tanpa kid
+func SetJWTKeys(signing JWTKey, verify []JWTKey, legacySecret []byte) {
+	set := &jwtKeySet{
+		signing: &signing,
+		byKid:   make(map[string]*JWTKey, len(verify)+1),
+		legacy:  legacySecret,
+	}
+	if signing.ID != "" {
+		set.byKid[signing.ID] = set.signing
+		set.ordered = append(set.ordered, set.signing)
+	}
+	for i := range verify {
+		if _, exists := set.byKid[verify[i].ID]; verify[i].ID != "" && !exists {
+			set.byKid[verify[i].ID] = &verify[i]
+			set.ordered = append(set.ordered, &verify[i])
+		}
+	}
+	jwtKeys.Store(set)
+}
+
+// signJWT - sign claims dengan key aktif, kid dan typ (jenis token) dicantumkan di header
+func signJWT(claims jwt.Claims, typ string) (string, error) {
+	set := jwtKeys.Load()
+	if set == nil || set.signing == nil || set.signing.PrivateKey == nil {
+		return "", errors.New("jwt signing key is not configured")
+	}
+
+	method, err := jwtSigningMethod(set.signing.Algorithm)
+	if err != nil {
+		return "", err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["typ"] = typ
+	if set.signing.ID != "" {
+		token.Header["kid"] = set.signing.ID
+	}
+	return token.SignedString(set.signing.PrivateKey)
+}
+
+// jwtKeyFunc - pilih key verify berdasarkan kid, algoritma token harus sama dengan algoritma key
+func jwtKeyFunc(t *jwt.Token) (interface{}, error) {
+	set := jwtKeys.Load()
+	if set == nil {
+		return nil, errors.New("jwt keys are not configured")
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	if kid == "" {
+		if set.legacy != nil && t.Method.Alg() == jwt.SigningMethodHS256.Alg() {
+			return set.legacy, nil
+		}
+		return nil, errors.New("token has no kid")
+	}
+
+	key, ok := set.byKid[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key %q", kid)
+	}
+	if t.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("unexpected signing method %s for key %q", t.Method.Alg(), kid)
+	}
+	return key.PublicKey, nil
+}
+
+func jwtSigningMethod(algorithm string) (jwt.SigningMethod, error) {
+	switch algorithm {
+	case "EdDSA":
+		return jwt.SigningMethodEdDSA, nil
+	case "RS256":
+		return jwt.SigningMethodRS256, nil
+	case "HS256":
+		return jwt.SigningMethodHS256, nil
+	default:
+		return nil, fmt.Errorf("unsupported jwt algorithm %q", algorithm)
+	}
+}
+
+// JWKS - public key semua key asimetris (untuk /.well-known/jwks.json), key HS256 tidak pernah dipublikasikan
+func JWKS() models.JWKSResponse {
+	response := models.JWKSResponse{Keys: []models.JWK{}}
+
+	set := jwtKeys.Load()
+	if set == nil {
+		return response
+	}
+
+	for _, key := range set.ordered {
+		jwk := models.JWK{Kid: key.ID, Use: "sig", Alg: key.Algorithm}
+		switch public := key.PublicKey.(type) {
+		case ed25519.PublicKey:
+			jwk.Kty, jwk.Crv = "OKP", "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(public)
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(public.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes())
+		default:
+			continue
+		}
+		response.Keys = append(response.Keys, jwk)
+	}
+	return response
+}
+
+// ==================== KEY PAIR ====================
+
+// rsaKeyBits - ukuran key RS256
+const rsaKeyBits = 2048
+
+// GenerateJWTKeyPair - buat key pair baru untuk algoritma EdDSA / RS256
+func GenerateJWTKeyPair(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case "EdDSA":
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+		return private, err
+	case "RS256":
+		return rsa.GenerateKey(rand.Reader, rsaKeyBits)
+	default:
+		return nil, fmt.Errorf("cannot generate key pair for jwt algorithm %q", algorithm)
+	}
+}
+
+// MarshalJWTKeyPair - private key dalam PKCS#8 DER dan public key dalam PKIX PEM
+func MarshalJWTKeyPair(private crypto.Signer) (privateDER []byte, publicPEM string, err error) {
+	privateDER, err = x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, "", err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, "", err
+	}
+
+	publicPEM = string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}))
+	return privateDER, publicPEM, nil
+}
+
+// ParseJWTPrivateKey - kebalikan MarshalJWTKeyPair, hanya menerima Ed25519 / RSA
+func ParseJWTPrivateKey(privateDER []byte) (crypto.Signer, error) {
+	key, err := x509.ParsePKCS8PrivateKey(privateDER)
+	if err != nil {
+		return nil, err
+	}
+
+	switch private := key.(type) {
+	case ed25519.PrivateKey:
+		return private, nil
+	case *rsa.PrivateKey:
+		return private, nil
+	default:
+		return nil, fmt.Errorf("unsupported private key type %T", key)
+	}
+}
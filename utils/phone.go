@@ -0,0 +1,44 @@
+package utils
+
+import "strings"
+
+// NormalizePhoneNumber - normalisasi nomor HP Indonesia ke format +62xxxx
+// Contoh: "0812-3456-7890", "62812 3456 7890", "+62 812 3456 7890" → "+6281234567890"
+// Return string kosong jika bukan nomor HP yang valid
+func NormalizePhoneNumber(raw string) string {
+	var digits strings.Builder
+	for i, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' && i == 0:
+			// "+" hanya boleh di depan
+		case r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+			// pemisah yang umum dipakai, abaikan
+		default:
+			return ""
+		}
+	}
+
+	number := digits.String()
+	switch {
+	case strings.HasPrefix(number, "62"):
+		number = number[2:]
+	case strings.HasPrefix(number, "0"):
+		number = number[1:]
+	}
+
+	// Nomor HP Indonesia diawali 8 dan panjangnya 9-13 digit (tanpa kode negara)
+	if !strings.HasPrefix(number, "8") || len(number) < 9 || len(number) > 13 {
+		return ""
+	}
+
+	return "+62" + number
+}
+
+// PhoneNumberVariants - semua format penulisan dari nomor yang sudah dinormalisasi
+// Dipakai untuk lookup data lama yang masih tersimpan dengan format 08xx / 628xx
+func PhoneNumberVariants(normalized string) []string {
+	local := strings.TrimPrefix(normalized, "+62")
+	return []string{normalized, "62" + local, "0" + local}
+}
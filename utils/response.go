@@ -1,14 +1,115 @@
 package utils
 
+import (
+	"errors"
 
-import "github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2"
+)
+
+// envelopeLocal - flag di c.Locals, true jika response harus dibungkus envelope (route /api/v1)
+const envelopeLocal = "responseEnvelope"
+
+// UseEnvelope → aktifkan format envelope untuk request ini (lihat middlewares.ResponseEnvelope)
+func UseEnvelope(c *fiber.Ctx) {
+	c.Locals(envelopeLocal, true)
+}
+
+// usesEnvelope → true jika request ini memakai format envelope
+// Route lama (/auth/*) tetap memakai format lama supaya frontend yang sudah deploy tidak rusak
+func usesEnvelope(c *fiber.Ctx) bool {
+	enabled, _ := c.Locals(envelopeLocal).(bool)
+	return enabled
+}
+
+// responseMeta → isi field "meta" di envelope
+func responseMeta(c *fiber.Ctx) fiber.Map {
+	requestID, _ := c.Locals("requestID").(string)
+	return fiber.Map{"request_id": requestID}
+}
 
 // JSONError → gunakan untuk return error
+// code diturunkan dari status (lihat ErrorCodeForStatus)
 func JSONError(c *fiber.Ctx, status int, message string) error {
-	return c.Status(status).JSON(fiber.Map{"message": message})
+	return JSONErrorCode(c, status, ErrorCodeForStatus(status), message, nil)
+}
+
+// JSONErrorCode → return error dengan code machine-readable dan (opsional) error per field
+// Format lama: {"code": "...", "message": "...", "details": {"field": ["..."]}}
+// Format envelope: {"error": {"code": "...", "message": "...", "details": {...}}, "meta": {"request_id": "..."}}
+func JSONErrorCode(c *fiber.Ctx, status int, code, message string, details map[string][]string) error {
+	body := fiber.Map{
+		"code":    code,
+		"message": message,
+	}
+	if len(details) > 0 {
+		body["details"] = details
+	}
+
+	if usesEnvelope(c) {
+		return c.Status(status).JSON(fiber.Map{
+			"error": body,
+			"meta":  responseMeta(c),
+		})
+	}
+
+	return c.Status(status).JSON(body)
 }
 
 // JSONSuccess → gunakan untuk return success response
+// Format envelope: {"data": ..., "meta": {"request_id": "..."}}
 func JSONSuccess(c *fiber.Ctx, status int, data interface{}) error {
+	if usesEnvelope(c) {
+		return c.Status(status).JSON(fiber.Map{
+			"data": data,
+			"meta": responseMeta(c),
+		})
+	}
+
 	return c.Status(status).JSON(data)
-}
\ No newline at end of file
+}
+
+// JSONValidationError → gunakan untuk return error validasi (422) dengan daftar error per field
+// Error selain *ValidationError dikembalikan sebagai 400
+func JSONValidationError(c *fiber.Ctx, err error) error {
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		return JSONError(c, fiber.StatusBadRequest, err.Error())
+	}
+
+	return JSONErrorCode(c, fiber.StatusUnprocessableEntity, "validation_failed", "Validation failed", validationErr.Fields)
+}
+
+// ErrorCodeForStatus → code default untuk error yang tidak punya code sendiri
+func ErrorCodeForStatus(status int) string {
+	switch status {
+	case fiber.StatusBadRequest:
+		return "bad_request"
+	case fiber.StatusUnauthorized:
+		return "unauthorized"
+	case fiber.StatusForbidden:
+		return "forbidden"
+	case fiber.StatusNotFound:
+		return "not_found"
+	case fiber.StatusMethodNotAllowed:
+		return "method_not_allowed"
+	case fiber.StatusConflict:
+		return "conflict"
+	case fiber.StatusRequestEntityTooLarge:
+		return "payload_too_large"
+	case fiber.StatusUnprocessableEntity:
+		return "validation_failed"
+	case fiber.StatusLocked:
+		return "locked"
+	case fiber.StatusTooManyRequests:
+		return "rate_limited"
+	case fiber.StatusBadGateway:
+		return "upstream_error"
+	case fiber.StatusServiceUnavailable:
+		return "service_unavailable"
+	}
+
+	if status >= 500 {
+		return "internal_error"
+	}
+	return "error"
+}
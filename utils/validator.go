@@ -0,0 +1,185 @@
+package utils
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Validasi deklaratif lewat struct tag `validate`, contoh:
+//
+//	type RegisterRequest struct {
+//		Email    string `json:"email" validate:"required,email,max=150"`
+//		Password string `json:"password" validate:"required"`
+//		Confirm  string `json:"confirmPassword" validate:"required,eqfield=Password"`
+//	}
+//
+// Rule yang didukung (hanya untuk field string):
+//   - required      : tidak boleh kosong (spasi saja dianggap kosong)
+//   - email         : format email
+//   - phone         : nomor HP Indonesia (08xx / 628xx / +628xx)
+//   - username      : 3-50 karakter huruf, angka, "_", "." atau "-", tidak boleh berupa nomor HP
+//   - min=N / max=N : panjang minimal / maksimal (jumlah karakter)
+//   - len=N         : panjang harus tepat N karakter
+//   - numeric       : hanya angka
+//   - oneof=a b c   : harus salah satu dari nilai yang dipisah spasi
+//   - eqfield=Field : harus sama dengan field lain di struct yang sama
+//
+// Rule selain required dilewati jika field kosong.
+// Nama field di error memakai nama dari tag `json` supaya frontend bisa langsung highlight input-nya.
+
+var (
+	emailRegex    = regexp.MustCompile(`^[^\s@]+@[^\s@]+\.[^\s@]+$`)
+	usernameRegex = regexp.MustCompile(`^[A-Za-z0-9_.-]{3,50}$`)
+	numericRegex  = regexp.MustCompile(`^[0-9]+$`)
+)
+
+// ValidationError - daftar pesan error per field (key = nama field JSON)
+type ValidationError struct {
+	Fields map[string][]string
+}
+
+func (e *ValidationError) Error() string {
+	names := make([]string, 0, len(e.Fields))
+	for name := range e.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parts := make([]string, 0, len(names))
+	for _, name := range names {
+		parts = append(parts, name+" "+strings.Join(e.Fields[name], ", "))
+	}
+	return "validation failed: " + strings.Join(parts, "; ")
+}
+
+// Add - tambah pesan error untuk field
+func (e *ValidationError) Add(field, message string) {
+	if e.Fields == nil {
+		e.Fields = make(map[string][]string)
+	}
+	e.Fields[field] = append(e.Fields[field], message)
+}
+
+// ValidateStruct - validasi struct (atau pointer ke struct) berdasarkan tag `validate`
+// Return *ValidationError jika ada field yang tidak valid, nil jika valid
+func ValidateStruct(s interface{}) error {
+	value := reflect.Indirect(reflect.ValueOf(s))
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("ValidateStruct: expected struct, got %s", value.Kind())
+	}
+
+	result := &ValidationError{}
+	structType := value.Type()
+
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("validate")
+		if tag == "" || field.Type.Kind() != reflect.String {
+			continue
+		}
+
+		name := jsonFieldName(field)
+		fieldValue := value.Field(i).String()
+
+		for _, rule := range strings.Split(tag, ",") {
+			ruleName, param, _ := strings.Cut(strings.TrimSpace(rule), "=")
+
+			if ruleName == "required" {
+				if strings.TrimSpace(fieldValue) == "" {
+					result.Add(name, "is required")
+					break
+				}
+				continue
+			}
+
+			if fieldValue == "" {
+				break
+			}
+
+			if message := checkRule(ruleName, param, fieldValue, value); message != "" {
+				result.Add(name, message)
+			}
+		}
+	}
+
+	if len(result.Fields) > 0 {
+		return result
+	}
+	return nil
+}
+
+// checkRule - jalankan satu rule, return pesan error atau string kosong jika valid
+func checkRule(rule, param, fieldValue string, parent reflect.Value) string {
+	switch rule {
+	case "email":
+		if !emailRegex.MatchString(fieldValue) {
+			return "must be a valid email address"
+		}
+	case "phone":
+		if NormalizePhoneNumber(fieldValue) == "" {
+			return "must be a valid Indonesian phone number (08xx / +628xx)"
+		}
+	case "username":
+		if !usernameRegex.MatchString(fieldValue) {
+			return "must be 3-50 characters of letters, numbers, \"_\", \".\" or \"-\""
+		}
+		// Login menganggap identifier berformat nomor HP sebagai nomor HP, username seperti itu tidak bisa dipakai login
+		if NormalizePhoneNumber(fieldValue) != "" {
+			return "must not be a phone number"
+		}
+	case "numeric":
+		if !numericRegex.MatchString(fieldValue) {
+			return "must contain only digits"
+		}
+	case "min", "max", "len":
+		n, err := strconv.Atoi(param)
+		if err != nil {
+			panic(fmt.Sprintf("validate: invalid %s parameter %q", rule, param))
+		}
+		length := utf8.RuneCountInString(fieldValue)
+		if rule == "min" && length < n {
+			return fmt.Sprintf("must be at least %d characters", n)
+		}
+		if rule == "max" && length > n {
+			return fmt.Sprintf("must be at most %d characters", n)
+		}
+		if rule == "len" && length != n {
+			return fmt.Sprintf("must be exactly %d characters", n)
+		}
+	case "oneof":
+		options := strings.Fields(param)
+		for _, option := range options {
+			if fieldValue == option {
+				return ""
+			}
+		}
+		return "must be one of: " + strings.Join(options, ", ")
+	case "eqfield":
+		other := parent.FieldByName(param)
+		if !other.IsValid() {
+			panic(fmt.Sprintf("validate: unknown eqfield %q", param))
+		}
+		if other.String() != fieldValue {
+			otherField, _ := parent.Type().FieldByName(param)
+			return "must match " + jsonFieldName(otherField)
+		}
+	default:
+		panic(fmt.Sprintf("validate: unknown rule %q", rule))
+	}
+
+	return ""
+}
+
+// jsonFieldName - nama field dari tag json (fallback ke nama field Go)
+func jsonFieldName(field reflect.StructField) string {
+	name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return field.Name
+	}
+	return name
+}